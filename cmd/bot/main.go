@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
@@ -27,7 +28,7 @@ func main() {
 
 	api, _ := maxbot.New(botToken)
 	storage := storage.NewMemoryStorage()
-	handler := handlers.New(*storage)
+	handler := handlers.New(storage)
 
 	botCtx := context.Background()
 	botInfo, err := api.Bots.GetBot(botCtx)
@@ -50,9 +51,24 @@ func main() {
 
 	fmt.Println("🚀 Starting to process updates...")
 
-	for update := range api.GetUpdates(ctx) {
-		handler.HandleUpdate(ctx, api, update)
-	}
+	// Периодические задачи (повторяющиеся задачи и т.п.) и сработавшие таймеры помодоро
+	// выполняются в том же цикле, что и обработка обновлений
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
 
-	fmt.Println("👋 Bot stopped")
+	updates := api.GetUpdates(ctx)
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				fmt.Println("👋 Bot stopped")
+				return
+			}
+			handler.HandleUpdate(ctx, api, update)
+		case now := <-ticker.C:
+			handler.Tick(ctx, api, now)
+		case fire := <-handler.Timers():
+			fire()
+		}
+	}
 }
@@ -2,13 +2,16 @@ module proddy-bot
 
 go 1.24.5
 
+require (
+	github.com/joho/godotenv v1.5.1
+	github.com/max-messenger/max-bot-api-client-go v1.0.3
+)
+
 require (
 	github.com/caarlos0/env/v6 v6.10.1 // indirect
 	github.com/google/uuid v1.6.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.19 // indirect
-	github.com/max-messenger/max-bot-api-client-go v1.0.3 // indirect
 	github.com/rs/zerolog v1.34.0 // indirect
 	golang.org/x/sys v0.12.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
@@ -0,0 +1,113 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"proddy-bot/internal/models"
+)
+
+// EstimateStats - оценки выполненных задач в сравнении с фактом
+type EstimateStats struct {
+	Tasks     int
+	Estimated int // помодоро по оценкам
+	Actual    int // помодоро на самом деле
+	Exact     int // задач, оцененных точно
+	Under     int // задач, потребовавших больше сессий, чем оценено
+	Over      int // задач, потребовавших меньше сессий, чем оценено
+}
+
+// Ratio - сколько процентов от оценки уходит на самом деле (больше 100 - задачи недооцениваются)
+func (s EstimateStats) Ratio() int {
+	if s.Estimated == 0 {
+		return 0
+	}
+	return s.Actual * 100 / s.Estimated
+}
+
+// ExactRate - доля точно оцененных задач в процентах
+func (s EstimateStats) ExactRate() int {
+	if s.Tasks == 0 {
+		return 0
+	}
+	return s.Exact * 100 / s.Tasks
+}
+
+func (s *EstimateStats) add(estimate, actual int) {
+	s.Tasks++
+	s.Estimated += estimate
+	s.Actual += actual
+	switch {
+	case actual > estimate:
+		s.Under++
+	case actual < estimate:
+		s.Over++
+	default:
+		s.Exact++
+	}
+}
+
+// WeekEstimates - оценки задач, выполненных за одну неделю
+type WeekEstimates struct {
+	Period Period
+	EstimateStats
+}
+
+// TaskEstimate - выполненная задача с оценкой и фактическим числом сессий
+type TaskEstimate struct {
+	Task   *models.Task
+	Actual int
+}
+
+// Estimates - точность оценок задач в помодоро
+type Estimates struct {
+	Total EstimateStats
+	// Weeks - по неделям выполнения задач, от старой к текущей
+	Weeks []WeekEstimates
+	// Tasks - выполненные задачи с оценкой, от недавних к старым
+	Tasks []TaskEstimate
+}
+
+// TaskPomodoros - сколько рабочих сессий завершено по каждой задаче
+func TaskPomodoros(sessions []*models.PomodoroSession) map[string]int {
+	counts := make(map[string]int)
+	for _, session := range sessions {
+		if session.Type == "work" && session.Completed && session.TaskID != "" {
+			counts[session.TaskID]++
+		}
+	}
+	return counts
+}
+
+// AnalyzeEstimates сравнивает оценки выполненных задач с фактическим числом сессий
+// за всю историю и по последним weeks неделям
+func AnalyzeEstimates(in Input, now time.Time, weeks int) Estimates {
+	var estimates Estimates
+	pomodoros := TaskPomodoros(in.Sessions)
+
+	for _, task := range in.Tasks {
+		if !task.Completed || task.CompletedAt == nil || task.Estimate <= 0 {
+			continue
+		}
+		actual := pomodoros[task.ID]
+		estimates.Total.add(task.Estimate, actual)
+		estimates.Tasks = append(estimates.Tasks, TaskEstimate{Task: task, Actual: actual})
+	}
+	sort.SliceStable(estimates.Tasks, func(i, j int) bool {
+		return estimates.Tasks[i].Task.CompletedAt.After(*estimates.Tasks[j].Task.CompletedAt)
+	})
+
+	current := LastDays(now, 7)
+	for week := weeks - 1; week >= 0; week-- {
+		period := Period{Start: current.Start.AddDate(0, 0, -7*week), End: current.End.AddDate(0, 0, -7*week)}
+		trend := WeekEstimates{Period: period}
+		for _, estimate := range estimates.Tasks {
+			if period.Contains(*estimate.Task.CompletedAt) {
+				trend.add(estimate.Task.Estimate, estimate.Actual)
+			}
+		}
+		estimates.Weeks = append(estimates.Weeks, trend)
+	}
+
+	return estimates
+}
@@ -0,0 +1,87 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"proddy-bot/internal/models"
+)
+
+func TestTaskPomodoros(t *testing.T) {
+	start := testNow.Add(-time.Hour)
+	sessions := []*models.PomodoroSession{
+		{StartTime: start, Type: "work", Completed: true, TaskID: "a"},
+		{StartTime: start, Type: "work", Completed: true, TaskID: "a"},
+		{StartTime: start, Type: "work", Interrupted: true, TaskID: "a"},
+		{StartTime: start, Type: "work", Completed: true, TaskID: "b"},
+		{StartTime: start, Type: "work", Completed: true},
+		{StartTime: start, Type: "short_break", Completed: true, TaskID: "b"},
+	}
+
+	counts := TaskPomodoros(sessions)
+	if counts["a"] != 2 || counts["b"] != 1 || len(counts) != 2 {
+		t.Errorf("TaskPomodoros() = %v, want a: 2, b: 1", counts)
+	}
+}
+
+func TestAnalyzeEstimates(t *testing.T) {
+	completedAt := func(daysAgo int) *time.Time {
+		at := testNow.AddDate(0, 0, -daysAgo)
+		return &at
+	}
+	sessions := func(taskID string, n int) []*models.PomodoroSession {
+		var result []*models.PomodoroSession
+		for i := 0; i < n; i++ {
+			result = append(result, &models.PomodoroSession{StartTime: testNow, Type: "work", Completed: true, TaskID: taskID})
+		}
+		return result
+	}
+
+	var in Input
+	in.Sessions = append(in.Sessions, sessions("exact", 3)...)
+	in.Sessions = append(in.Sessions, sessions("under", 4)...)
+	in.Sessions = append(in.Sessions, sessions("over", 1)...)
+	in.Sessions = append(in.Sessions, sessions("old", 2)...)
+	in.Tasks = []*models.Task{
+		{ID: "exact", Estimate: 3, Completed: true, CompletedAt: completedAt(0)},
+		{ID: "under", Estimate: 2, Completed: true, CompletedAt: completedAt(1)},
+		{ID: "over", Estimate: 3, Completed: true, CompletedAt: completedAt(2)},
+		{ID: "old", Estimate: 4, Completed: true, CompletedAt: completedAt(10)},
+		{ID: "open", Estimate: 2},
+		{ID: "no estimate", Completed: true, CompletedAt: completedAt(0)},
+	}
+
+	estimates := AnalyzeEstimates(in, testNow, 2)
+
+	total := estimates.Total
+	if total.Tasks != 4 || total.Estimated != 12 || total.Actual != 10 {
+		t.Errorf("Total = %+v, want 4 tasks, 12 estimated, 10 actual", total)
+	}
+	if total.Exact != 1 || total.Under != 1 || total.Over != 2 {
+		t.Errorf("Total = %+v, want 1 exact, 1 under, 2 over", total)
+	}
+	if total.Ratio() != 83 || total.ExactRate() != 25 {
+		t.Errorf("Ratio() = %d, ExactRate() = %d, want 83 and 25", total.Ratio(), total.ExactRate())
+	}
+
+	if len(estimates.Tasks) != 4 || estimates.Tasks[0].Task.ID != "exact" || estimates.Tasks[3].Task.ID != "old" {
+		t.Errorf("Tasks must be sorted from recent to old, got %+v", estimates.Tasks)
+	}
+
+	if len(estimates.Weeks) != 2 {
+		t.Fatalf("len(Weeks) = %d, want 2", len(estimates.Weeks))
+	}
+	if previous, current := estimates.Weeks[0], estimates.Weeks[1]; previous.Tasks != 1 || current.Tasks != 3 {
+		t.Errorf("Weeks = %d and %d tasks, want 1 and 3", previous.Tasks, current.Tasks)
+	}
+	if current := estimates.Weeks[1]; !current.Period.End.Equal(Week(testNow).End) {
+		t.Errorf("current week ends at %v, want %v", current.Period.End, Week(testNow).End)
+	}
+}
+
+func TestEstimateStatsEmpty(t *testing.T) {
+	var stats EstimateStats
+	if stats.Ratio() != 0 || stats.ExactRate() != 0 {
+		t.Errorf("empty stats: Ratio() = %d, ExactRate() = %d, want 0", stats.Ratio(), stats.ExactRate())
+	}
+}
@@ -0,0 +1,141 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+)
+
+// trendWeeks - за сколько последних недель считается динамика прерываний
+const trendWeeks = 4
+
+// Bucket - рабочие сессии, начатые в один час суток или день недели
+type Bucket struct {
+	Sessions    int
+	Completed   int
+	Interrupted int
+	Minutes     int
+}
+
+// CompletionRate - доля доведенных до конца сессий в процентах
+func (b Bucket) CompletionRate() int {
+	if b.Sessions == 0 {
+		return 0
+	}
+	return b.Completed * 100 / b.Sessions
+}
+
+// InterruptionRate - доля прерванных сессий в процентах
+func (b Bucket) InterruptionRate() int {
+	if b.Sessions == 0 {
+		return 0
+	}
+	return b.Interrupted * 100 / b.Sessions
+}
+
+// WeekInterruptions - прерывания за одну неделю
+type WeekInterruptions struct {
+	Period Period
+	Bucket
+}
+
+// Insights - закономерности в работе пользователя
+type Insights struct {
+	Total        Bucket
+	ByHour       [24]Bucket
+	ByWeekday    [7]Bucket
+	BestHours    []int
+	BestWeekdays []time.Weekday
+
+	// Interruptions - прерывания по неделям, от старой к текущей
+	Interruptions []WeekInterruptions
+
+	// CompletedTasks и AvgCompletion - сколько задач выполнено и сколько в среднем
+	// проходит от создания задачи до ее выполнения
+	CompletedTasks int
+	AvgCompletion  time.Duration
+
+	// Estimates - точность оценок задач в помодоро
+	Estimates Estimates
+}
+
+// Analyze ищет закономерности во всей истории пользователя
+func Analyze(in Input, now time.Time) Insights {
+	var insights Insights
+
+	for _, session := range in.Sessions {
+		// Идущая сейчас сессия еще ни завершена, ни прервана - в закономерности не попадает
+		if session.Type != "work" || (!session.Completed && !session.Interrupted) {
+			continue
+		}
+		start := session.StartTime.In(now.Location())
+		for _, bucket := range []*Bucket{&insights.Total, &insights.ByHour[start.Hour()], &insights.ByWeekday[start.Weekday()]} {
+			countSession(bucket, session.Completed, session.Interrupted, FocusMinutes(session))
+		}
+	}
+
+	insights.BestHours = bestIndexes(insights.ByHour[:], 3)
+	for _, day := range bestIndexes(insights.ByWeekday[:], 2) {
+		insights.BestWeekdays = append(insights.BestWeekdays, time.Weekday(day))
+	}
+
+	current := LastDays(now, 7)
+	for week := 0; week < trendWeeks; week++ {
+		period := Period{Start: current.Start.AddDate(0, 0, -7*week), End: current.End.AddDate(0, 0, -7*week)}
+		trend := WeekInterruptions{Period: period}
+		for _, session := range in.Sessions {
+			if session.Type == "work" && (session.Completed || session.Interrupted) && period.Contains(session.StartTime) {
+				countSession(&trend.Bucket, session.Completed, session.Interrupted, FocusMinutes(session))
+			}
+		}
+		insights.Interruptions = append([]WeekInterruptions{trend}, insights.Interruptions...)
+	}
+
+	var total time.Duration
+	for _, task := range in.Tasks {
+		if !task.Completed || task.CompletedAt == nil || task.CompletedAt.Before(task.Created) {
+			continue
+		}
+		insights.CompletedTasks++
+		total += task.CompletedAt.Sub(task.Created)
+	}
+	if insights.CompletedTasks > 0 {
+		insights.AvgCompletion = total / time.Duration(insights.CompletedTasks)
+	}
+
+	insights.Estimates = AnalyzeEstimates(in, now, trendWeeks)
+
+	return insights
+}
+
+func countSession(bucket *Bucket, completed, interrupted bool, minutes int) {
+	bucket.Sessions++
+	if completed {
+		bucket.Completed++
+	}
+	if interrupted {
+		bucket.Interrupted++
+	}
+	bucket.Minutes += minutes
+}
+
+// bestIndexes возвращает до limit индексов с наибольшими минутами фокуса
+// (при равенстве - с меньшей долей прерываний)
+func bestIndexes(buckets []Bucket, limit int) []int {
+	var indexes []int
+	for i, bucket := range buckets {
+		if bucket.Minutes > 0 {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		left, right := buckets[indexes[a]], buckets[indexes[b]]
+		if left.Minutes != right.Minutes {
+			return left.Minutes > right.Minutes
+		}
+		return left.InterruptionRate() < right.InterruptionRate()
+	})
+	if len(indexes) > limit {
+		indexes = indexes[:limit]
+	}
+	return indexes
+}
@@ -0,0 +1,140 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"proddy-bot/internal/models"
+)
+
+func TestBestIndexes(t *testing.T) {
+	tests := []struct {
+		name    string
+		buckets []Bucket
+		limit   int
+		want    []int
+	}{
+		{
+			name:    "empty buckets are skipped",
+			buckets: []Bucket{{}, {Minutes: 10}, {}},
+			limit:   3,
+			want:    []int{1},
+		},
+		{
+			name:    "sorted by minutes",
+			buckets: []Bucket{{Minutes: 10}, {Minutes: 50}, {Minutes: 25}},
+			limit:   3,
+			want:    []int{1, 2, 0},
+		},
+		{
+			name:    "limited",
+			buckets: []Bucket{{Minutes: 10}, {Minutes: 50}, {Minutes: 25}},
+			limit:   2,
+			want:    []int{1, 2},
+		},
+		{
+			name: "ties broken by interruption rate",
+			buckets: []Bucket{
+				{Sessions: 2, Interrupted: 1, Minutes: 25},
+				{Sessions: 2, Interrupted: 0, Minutes: 25},
+			},
+			limit: 2,
+			want:  []int{1, 0},
+		},
+		{
+			name:    "no focus at all",
+			buckets: []Bucket{{}, {}},
+			limit:   2,
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bestIndexes(tt.buckets, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bestIndexes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBucketRates(t *testing.T) {
+	tests := []struct {
+		bucket       Bucket
+		completion   int
+		interruption int
+	}{
+		{Bucket{}, 0, 0},
+		{Bucket{Sessions: 4, Completed: 3, Interrupted: 1}, 75, 25},
+		{Bucket{Sessions: 3, Completed: 1, Interrupted: 2}, 33, 66},
+	}
+
+	for _, tt := range tests {
+		if got := tt.bucket.CompletionRate(); got != tt.completion {
+			t.Errorf("%+v CompletionRate() = %d, want %d", tt.bucket, got, tt.completion)
+		}
+		if got := tt.bucket.InterruptionRate(); got != tt.interruption {
+			t.Errorf("%+v InterruptionRate() = %d, want %d", tt.bucket, got, tt.interruption)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	at := func(daysAgo, hour int) time.Time {
+		return time.Date(2024, time.May, 15-daysAgo, hour, 0, 0, 0, time.UTC)
+	}
+	created := at(3, 9)
+	completedAt := created.Add(6 * time.Hour)
+
+	in := Input{
+		Sessions: []*models.PomodoroSession{
+			workSession(at(0, 9), true),
+			workSession(at(1, 9), true),
+			workSession(at(2, 9), true),
+			workSession(at(0, 14), true),
+			workSession(at(1, 14), false),
+			workSession(at(1, 20), false),
+			{StartTime: at(0, 17), Duration: 25, Type: "work"},
+			{StartTime: at(0, 11), Duration: 5, Type: "short_break", Completed: true},
+		},
+		Tasks: []*models.Task{
+			{Created: created, Completed: true, CompletedAt: &completedAt},
+			{Created: created},
+		},
+	}
+
+	insights := Analyze(in, testNow)
+
+	if insights.Total.Sessions != 6 || insights.Total.Completed != 4 || insights.Total.Interrupted != 2 {
+		t.Errorf("Total = %+v, want 6 sessions, 4 completed, 2 interrupted", insights.Total)
+	}
+	if want := []int{9, 14}; !reflect.DeepEqual(insights.BestHours, want) {
+		t.Errorf("BestHours = %v, want %v", insights.BestHours, want)
+	}
+	if got := insights.ByHour[9].Minutes; got != 75 {
+		t.Errorf("ByHour[9].Minutes = %d, want 75", got)
+	}
+	if got := insights.ByHour[20]; got.Sessions != 1 || got.Minutes != 0 {
+		t.Errorf("ByHour[20] = %+v, want one session without focus minutes", got)
+	}
+	if len(insights.Interruptions) != trendWeeks {
+		t.Fatalf("len(Interruptions) = %d, want %d", len(insights.Interruptions), trendWeeks)
+	}
+	if current := insights.Interruptions[trendWeeks-1]; current.Sessions != 6 || current.Interrupted != 2 {
+		t.Errorf("current week = %+v, want 6 sessions, 2 interrupted", current.Bucket)
+	}
+	if insights.CompletedTasks != 1 || insights.AvgCompletion != 6*time.Hour {
+		t.Errorf("CompletedTasks = %d, AvgCompletion = %v, want 1 and 6h", insights.CompletedTasks, insights.AvgCompletion)
+	}
+}
+
+func TestAnalyzeEmpty(t *testing.T) {
+	insights := Analyze(Input{}, testNow)
+	if insights.Total.Sessions != 0 || len(insights.BestHours) != 0 || len(insights.BestWeekdays) != 0 {
+		t.Errorf("Analyze(empty) = %+v, want no sessions and no best hours", insights)
+	}
+	if insights.CompletedTasks != 0 || insights.AvgCompletion != 0 {
+		t.Errorf("Analyze(empty) tasks = %d, %v, want 0", insights.CompletedTasks, insights.AvgCompletion)
+	}
+}
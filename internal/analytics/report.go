@@ -0,0 +1,188 @@
+package analytics
+
+import (
+	"time"
+
+	"proddy-bot/internal/models"
+)
+
+// Period - полуинтервал [Start, End)
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains сообщает, попадает ли момент в период
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// Days возвращает количество дней в периоде
+func (p Period) Days() int {
+	return int(p.End.Sub(p.Start).Hours()/24 + 0.5)
+}
+
+// Previous возвращает такой же по длине период непосредственно перед этим
+func (p Period) Previous() Period {
+	days := p.Days()
+	return Period{Start: p.Start.AddDate(0, 0, -days), End: p.Start}
+}
+
+// LastDays возвращает последние n дней, включая сегодняшний
+func LastDays(now time.Time, n int) Period {
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	return Period{Start: end.AddDate(0, 0, -n), End: end}
+}
+
+// Week - последние 7 дней
+func Week(now time.Time) Period {
+	return LastDays(now, 7)
+}
+
+// Month - последние 30 дней
+func Month(now time.Time) Period {
+	return LastDays(now, 30)
+}
+
+// Input - история пользователя, по которой строятся отчеты.
+// Tasks должны включать и задачи из архива.
+type Input struct {
+	Sessions []*models.PomodoroSession
+	Tasks    []*models.Task
+	Goals    []*models.Goal
+}
+
+// DayFocus - минуты фокуса за один день
+type DayFocus struct {
+	Date    time.Time
+	Minutes int
+}
+
+// DayTasks - задачи, созданные и выполненные за один день
+type DayTasks struct {
+	Date      time.Time
+	Created   int
+	Completed int
+}
+
+// Report - показатели продуктивности за период
+type Report struct {
+	Period         Period
+	FocusByDay     []DayFocus
+	TasksByDay     []DayTasks
+	FocusMinutes   int
+	Sessions       int
+	Distractions   int
+	TasksCreated   int
+	TasksCompleted int
+	GoalsAdvanced  int
+	GoalsCompleted int
+}
+
+// Build считает показатели за период. Дни считаются в часовом поясе начала периода.
+func Build(in Input, period Period) Report {
+	report := Report{Period: period}
+	location := period.Start.Location()
+
+	byDay := make(map[string]int)
+	for _, session := range in.Sessions {
+		if session.Type != "work" || !period.Contains(session.StartTime) {
+			continue
+		}
+		report.Distractions += len(session.Distractions)
+		minutes := FocusMinutes(session)
+		if minutes == 0 {
+			continue
+		}
+		if session.Completed {
+			report.Sessions++
+		}
+		report.FocusMinutes += minutes
+		byDay[session.StartTime.In(location).Format("2006-01-02")] += minutes
+	}
+	for day := period.Start; day.Before(period.End); day = day.AddDate(0, 0, 1) {
+		report.FocusByDay = append(report.FocusByDay, DayFocus{Date: day, Minutes: byDay[day.Format("2006-01-02")]})
+	}
+
+	created, completed := make(map[string]int), make(map[string]int)
+	for _, task := range in.Tasks {
+		if period.Contains(task.Created) {
+			report.TasksCreated++
+			created[task.Created.In(location).Format("2006-01-02")]++
+		}
+		if task.Completed && task.CompletedAt != nil && period.Contains(*task.CompletedAt) {
+			report.TasksCompleted++
+			completed[task.CompletedAt.In(location).Format("2006-01-02")]++
+		}
+	}
+	for day := period.Start; day.Before(period.End); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		report.TasksByDay = append(report.TasksByDay, DayTasks{Date: day, Created: created[key], Completed: completed[key]})
+	}
+
+	for _, goal := range in.Goals {
+		if goalAdvanced(goal, period) {
+			report.GoalsAdvanced++
+		}
+		if goal.Completed && goal.CompletedAt != nil && period.Contains(*goal.CompletedAt) {
+			report.GoalsCompleted++
+		}
+	}
+
+	return report
+}
+
+// FocusMinutes - сколько минут фокуса засчитывается за рабочую сессию
+func FocusMinutes(session *models.PomodoroSession) int {
+	if session.Completed {
+		return session.Duration
+	}
+	if session.Interrupted {
+		return session.FocusedMinutes
+	}
+	return 0
+}
+
+// FocusStreak - сколько дней подряд пользователь завершал рабочие сессии.
+// Серия не прерывается, если сегодня сессий еще не было, но вчера были.
+func FocusStreak(sessions []*models.PomodoroSession, now time.Time) int {
+	days := make(map[string]bool)
+	for _, session := range sessions {
+		if session.Type == "work" && session.Completed {
+			days[session.StartTime.In(now.Location()).Format("2006-01-02")] = true
+		}
+	}
+
+	day := now
+	if !days[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+	streak := 0
+	for days[day.Format("2006-01-02")] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// goalAdvanced - вырос ли прогресс цели за период по сравнению с его началом
+func goalAdvanced(goal *models.Goal, period Period) bool {
+	before, best := 0, -1
+	for _, checkIn := range goal.CheckIns {
+		switch {
+		case checkIn.Date.Before(period.Start):
+			before = checkIn.Progress
+		case period.Contains(checkIn.Date) && checkIn.Progress > best:
+			best = checkIn.Progress
+		}
+	}
+	return best > before
+}
+
+// Change - относительное изменение показателя в процентах; ok=false, если сравнивать не с чем
+func Change(current, previous int) (int, bool) {
+	if previous == 0 {
+		return 0, false
+	}
+	return (current - previous) * 100 / previous, true
+}
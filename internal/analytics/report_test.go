@@ -0,0 +1,179 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"proddy-bot/internal/models"
+)
+
+// testNow - среда, 15 мая 2024, 18:00
+var testNow = time.Date(2024, time.May, 15, 18, 0, 0, 0, time.UTC)
+
+func workSession(start time.Time, completed bool) *models.PomodoroSession {
+	return &models.PomodoroSession{
+		ID:          start.Format(time.RFC3339),
+		StartTime:   start,
+		Duration:    25,
+		Type:        "work",
+		Completed:   completed,
+		Interrupted: !completed,
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	week := Week(testNow)
+	if want := time.Date(2024, time.May, 9, 0, 0, 0, 0, time.UTC); !week.Start.Equal(want) {
+		t.Errorf("Week().Start = %v, want %v", week.Start, want)
+	}
+	if want := time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC); !week.End.Equal(want) {
+		t.Errorf("Week().End = %v, want %v", week.End, want)
+	}
+	if week.Days() != 7 || Month(testNow).Days() != 30 {
+		t.Errorf("Days() = %d and %d, want 7 and 30", week.Days(), Month(testNow).Days())
+	}
+	if previous := week.Previous(); !previous.End.Equal(week.Start) || previous.Days() != 7 {
+		t.Errorf("Previous() = %+v, want 7 days before %v", previous, week.Start)
+	}
+	if !week.Contains(week.Start) || week.Contains(week.End) {
+		t.Errorf("Contains() must include Start and exclude End")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	at := func(daysAgo, hour int) time.Time {
+		return time.Date(2024, time.May, 15-daysAgo, hour, 0, 0, 0, time.UTC)
+	}
+	completedAt, oldCompletedAt := at(0, 12), at(1, 12)
+
+	in := Input{
+		Sessions: []*models.PomodoroSession{
+			workSession(at(0, 9), true),
+			workSession(at(1, 9), true),
+			workSession(at(1, 14), false),
+			workSession(at(8, 9), true),
+			{StartTime: at(0, 17), Duration: 25, Type: "work"},
+			{StartTime: at(0, 10), Duration: 5, Type: "short_break", Completed: true},
+		},
+		Tasks: []*models.Task{
+			{Created: at(2, 9), Completed: true, CompletedAt: &completedAt},
+			{Created: at(10, 9), Completed: true, CompletedAt: &oldCompletedAt},
+			{Created: at(0, 9)},
+			{Created: at(10, 9)},
+		},
+		Goals: []*models.Goal{
+			{CheckIns: []models.GoalCheckIn{{Date: at(10, 9), Progress: 20}, {Date: at(2, 9), Progress: 40}}},
+			{CheckIns: []models.GoalCheckIn{{Date: at(10, 9), Progress: 20}, {Date: at(2, 9), Progress: 10}}},
+			{Completed: true, CompletedAt: &completedAt, CheckIns: []models.GoalCheckIn{{Date: completedAt, Progress: 100}}},
+		},
+	}
+
+	report := Build(in, Week(testNow))
+
+	if report.FocusMinutes != 50 || report.Sessions != 2 {
+		t.Errorf("FocusMinutes = %d, Sessions = %d, want 50 and 2", report.FocusMinutes, report.Sessions)
+	}
+	if len(report.FocusByDay) != 7 {
+		t.Fatalf("len(FocusByDay) = %d, want 7", len(report.FocusByDay))
+	}
+	if today, yesterday := report.FocusByDay[6], report.FocusByDay[5]; today.Minutes != 25 || yesterday.Minutes != 25 || !today.Date.Equal(at(0, 0)) {
+		t.Errorf("FocusByDay = %+v, %+v, want 25 minutes today and yesterday", yesterday, today)
+	}
+	if report.TasksCreated != 2 || report.TasksCompleted != 2 {
+		t.Errorf("TasksCreated = %d, TasksCompleted = %d, want 2 and 2", report.TasksCreated, report.TasksCompleted)
+	}
+	if report.GoalsAdvanced != 2 || report.GoalsCompleted != 1 {
+		t.Errorf("GoalsAdvanced = %d, GoalsCompleted = %d, want 2 and 1", report.GoalsAdvanced, report.GoalsCompleted)
+	}
+
+	if empty := Build(Input{}, Week(testNow)); empty.FocusMinutes != 0 || len(empty.FocusByDay) != 7 {
+		t.Errorf("Build(empty) = %+v, want 7 empty days", empty)
+	}
+}
+
+func TestBuildUserTimezone(t *testing.T) {
+	moscow := time.FixedZone("UTC+3", 3*60*60)
+	// 22:30 UTC 14 мая - уже 15 мая по времени пользователя
+	in := Input{Sessions: []*models.PomodoroSession{workSession(time.Date(2024, time.May, 14, 22, 30, 0, 0, time.UTC), true)}}
+
+	report := Build(in, Week(testNow.In(moscow)))
+	if today := report.FocusByDay[6]; today.Minutes != 25 {
+		t.Errorf("FocusByDay today = %+v, want the session counted on the user's day", today)
+	}
+}
+
+func TestFocusMinutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *models.PomodoroSession
+		want    int
+	}{
+		{"completed", &models.PomodoroSession{Duration: 25, Completed: true}, 25},
+		{"interrupted counts focused minutes", &models.PomodoroSession{Duration: 25, Interrupted: true, FocusedMinutes: 12}, 12},
+		{"running", &models.PomodoroSession{Duration: 25}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FocusMinutes(tt.session); got != tt.want {
+				t.Errorf("FocusMinutes() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChange(t *testing.T) {
+	tests := []struct {
+		current, previous int
+		want              int
+		ok                bool
+	}{
+		{150, 100, 50, true},
+		{50, 100, -50, true},
+		{100, 100, 0, true},
+		{10, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := Change(tt.current, tt.previous); got != tt.want || ok != tt.ok {
+			t.Errorf("Change(%d, %d) = %d, %v, want %d, %v", tt.current, tt.previous, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFocusStreak(t *testing.T) {
+	day := func(daysAgo int) time.Time {
+		return testNow.AddDate(0, 0, -daysAgo).Add(-8 * time.Hour)
+	}
+	sessions := func(completed bool, days ...int) []*models.PomodoroSession {
+		var result []*models.PomodoroSession
+		for _, d := range days {
+			result = append(result, workSession(day(d), completed))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		sessions []*models.PomodoroSession
+		want     int
+	}{
+		{"no sessions", nil, 0},
+		{"today only", sessions(true, 0), 1},
+		{"several sessions a day count once", sessions(true, 0, 0, 1), 2},
+		{"three days in a row", sessions(true, 0, 1, 2), 3},
+		{"not yet today keeps yesterday's streak", sessions(true, 1, 2), 2},
+		{"gap breaks the streak", sessions(true, 0, 1, 3, 4), 2},
+		{"missed yesterday and today", sessions(true, 2, 3), 0},
+		{"interrupted sessions do not count", sessions(false, 0, 1), 0},
+		{"breaks do not count", []*models.PomodoroSession{{StartTime: day(0), Type: "short_break", Completed: true}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FocusStreak(tt.sessions, testNow); got != tt.want {
+				t.Errorf("FocusStreak() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Package charts рисует графики статистики в PNG без внешних зависимостей.
+// Подписи к графикам бот отправляет текстом, поэтому на картинках только данные.
+package charts
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+)
+
+const (
+	width   = 640
+	height  = 320
+	padding = 24
+
+	heatmapCell = 14
+	heatmapGap  = 3
+)
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	gridColor  = color.RGBA{0xe5, 0xe7, 0xeb, 0xff}
+	axisColor  = color.RGBA{0x9c, 0xa3, 0xaf, 0xff}
+	barColor   = color.RGBA{0x4a, 0x90, 0xe2, 0xff}
+
+	// seriesColors - цвета линий по порядку серий
+	seriesColors = []color.RGBA{
+		{0x2d, 0xa4, 0x4e, 0xff},
+		{0xb0, 0xb7, 0xc3, 0xff},
+	}
+
+	// heatmapLevels - шкала интенсивности как в календаре активности GitHub
+	heatmapLevels = []color.RGBA{
+		{0xeb, 0xed, 0xf0, 0xff},
+		{0x9b, 0xe9, 0xa8, 0xff},
+		{0x40, 0xc4, 0x63, 0xff},
+		{0x30, 0xa1, 0x4e, 0xff},
+		{0x21, 0x6e, 0x39, 0xff},
+	}
+)
+
+// Bar рисует столбчатую диаграмму (например, минуты фокуса по дням)
+func Bar(values []int) image.Image {
+	img := canvas(width, height)
+	drawGrid(img)
+	if len(values) == 0 {
+		return img
+	}
+
+	max := maxOf(values)
+	slot := (width - 2*padding) / len(values)
+	gap := slot / 5
+	for i, value := range values {
+		if value <= 0 || max == 0 {
+			continue
+		}
+		top := height - padding - value*(height-2*padding)/max
+		x := padding + i*slot
+		fill(img, image.Rect(x+gap/2, top, x+slot-gap/2, height-padding), barColor)
+	}
+	return img
+}
+
+// Line рисует линейный график; первая серия - основная, остальные выводятся бледнее
+func Line(series ...[]int) image.Image {
+	img := canvas(width, height)
+	drawGrid(img)
+
+	max := 0
+	for _, values := range series {
+		if m := maxOf(values); m > max {
+			max = m
+		}
+	}
+
+	// Основную серию рисуем последней, чтобы она была поверх остальных
+	for s := len(series) - 1; s >= 0; s-- {
+		values := series[s]
+		if len(values) == 0 {
+			continue
+		}
+		c := seriesColors[min(s, len(seriesColors)-1)]
+		points := make([]image.Point, len(values))
+		for i, value := range values {
+			points[i] = linePoint(i, len(values), value, max)
+		}
+		for i := 1; i < len(points); i++ {
+			drawLine(img, points[i-1], points[i], c)
+		}
+		for _, p := range points {
+			fill(img, image.Rect(p.X-3, p.Y-3, p.X+4, p.Y+4), c)
+		}
+	}
+	return img
+}
+
+// Heatmap рисует календарь активности: столбцы - недели, строки - дни с понедельника по воскресенье.
+// values - значения по дням подряд, first - день недели первого значения.
+func Heatmap(values []int, first time.Weekday) image.Image {
+	offset := (int(first) + 6) % 7
+	weeks := (offset + len(values) + 6) / 7
+	step := heatmapCell + heatmapGap
+	img := canvas(2*padding+weeks*step-heatmapGap, 2*padding+7*step-heatmapGap)
+
+	max := maxOf(values)
+	for i, value := range values {
+		cell := offset + i
+		x := padding + cell/7*step
+		y := padding + cell%7*step
+		fill(img, image.Rect(x, y, x+heatmapCell, y+heatmapCell), heatmapLevel(value, max))
+	}
+	return img
+}
+
+// EncodePNG кодирует картинку в PNG для загрузки в MAX
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func heatmapLevel(value, max int) color.RGBA {
+	if value <= 0 || max == 0 {
+		return heatmapLevels[0]
+	}
+	levels := len(heatmapLevels) - 1
+	level := 1 + (value*levels-1)/max
+	return heatmapLevels[min(level, levels)]
+}
+
+func canvas(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+	return img
+}
+
+// drawGrid рисует горизонтальные линии сетки и ось X
+func drawGrid(img *image.RGBA) {
+	const lines = 4
+	for i := 0; i < lines; i++ {
+		y := padding + i*(height-2*padding)/lines
+		fill(img, image.Rect(padding, y, width-padding, y+1), gridColor)
+	}
+	fill(img, image.Rect(padding, height-padding, width-padding, height-padding+2), axisColor)
+}
+
+func linePoint(i, count, value, max int) image.Point {
+	x := padding
+	if count > 1 {
+		x += i * (width - 2*padding) / (count - 1)
+	}
+	y := height - padding
+	if max > 0 {
+		y -= value * (height - 2*padding) / max
+	}
+	return image.Point{X: x, Y: y}
+}
+
+// drawLine рисует отрезок толщиной 3 пикселя
+func drawLine(img *image.RGBA, from, to image.Point, c color.RGBA) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	steps := max(abs(dx), abs(dy), 1)
+	for i := 0; i <= steps; i++ {
+		x := from.X + dx*i/steps
+		y := from.Y + dy*i/steps
+		fill(img, image.Rect(x-1, y-1, x+2, y+2), c)
+	}
+}
+
+func fill(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	draw.Draw(img, rect, &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+func maxOf(values []int) int {
+	result := 0
+	for _, value := range values {
+		if value > result {
+			result = value
+		}
+	}
+	return result
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,136 @@
+// Package gamification начисляет опыт за события продуктивности и выдает достижения.
+// Пакет не зависит от бота: обработчики передают событие и текущие показатели пользователя.
+package gamification
+
+import (
+	"time"
+
+	"proddy-bot/internal/models"
+)
+
+// EventType - вид события, за которое начисляется опыт
+type EventType string
+
+const (
+	PomodoroCompleted EventType = "pomodoro"
+	TaskCompleted     EventType = "task"
+	GoalCompleted     EventType = "goal"
+)
+
+// xpRewards - опыт за каждое событие
+var xpRewards = map[EventType]int{
+	PomodoroCompleted: 10,
+	TaskCompleted:     5,
+	GoalCompleted:     50,
+}
+
+// Event - событие из сценариев помодоро, задач и целей.
+// ID - идентификатор сессии, задачи или цели: за одну сущность опыт начисляется один раз.
+type Event struct {
+	Type EventType
+	ID   string
+	Time time.Time
+}
+
+// Stats - показатели пользователя на момент события, по которым проверяются достижения
+type Stats struct {
+	Pomodoros      int
+	PomodorosToday int
+	FocusStreak    int
+	Tasks          int
+	TasksToday     int
+	Goals          int
+}
+
+// Achievement - достижение и условие его получения
+type Achievement struct {
+	ID          string
+	Icon        string
+	Title       string
+	Description string
+	unlocked    func(Stats) bool
+}
+
+// Achievements - все достижения в порядке показа
+var Achievements = []Achievement{
+	{ID: "first_pomodoro", Icon: "🍅", Title: "Первый помидор", Description: "завершить первую помодоро-сессию",
+		unlocked: func(s Stats) bool { return s.Pomodoros >= 1 }},
+	{ID: "pomodoro_day_10", Icon: "🔥", Title: "10 помодоро за день", Description: "завершить 10 сессий за один день",
+		unlocked: func(s Stats) bool { return s.PomodorosToday >= 10 }},
+	{ID: "pomodoro_100", Icon: "💯", Title: "Сотня помидоров", Description: "завершить 100 помодоро-сессий",
+		unlocked: func(s Stats) bool { return s.Pomodoros >= 100 }},
+	{ID: "streak_7", Icon: "📅", Title: "7-дневная серия", Description: "фокусироваться 7 дней подряд",
+		unlocked: func(s Stats) bool { return s.FocusStreak >= 7 }},
+	{ID: "streak_30", Icon: "🗓", Title: "30-дневная серия", Description: "фокусироваться 30 дней подряд",
+		unlocked: func(s Stats) bool { return s.FocusStreak >= 30 }},
+	{ID: "first_task", Icon: "✅", Title: "Первая галочка", Description: "выполнить первую задачу",
+		unlocked: func(s Stats) bool { return s.Tasks >= 1 }},
+	{ID: "tasks_day_10", Icon: "⚡", Title: "Продуктивный день", Description: "выполнить 10 задач за один день",
+		unlocked: func(s Stats) bool { return s.TasksToday >= 10 }},
+	{ID: "tasks_50", Icon: "📝", Title: "50 задач", Description: "выполнить 50 задач",
+		unlocked: func(s Stats) bool { return s.Tasks >= 50 }},
+	{ID: "first_goal", Icon: "🏆", Title: "Цель достигнута", Description: "достичь первой цели",
+		unlocked: func(s Stats) bool { return s.Goals >= 1 }},
+	{ID: "goals_5", Icon: "🚀", Title: "Целеустремленный", Description: "достичь 5 целей",
+		unlocked: func(s Stats) bool { return s.Goals >= 5 }},
+}
+
+// Result - что пользователь получил за событие
+type Result struct {
+	XP       int
+	Level    int
+	LevelUp  bool
+	Unlocked []Achievement
+}
+
+// Apply начисляет опыт за событие и выдает новые достижения.
+// Повторное событие с тем же ID (например, задачу выполнили снова после отмены) опыт не приносит.
+func Apply(experience *models.Experience, event Event, stats Stats) Result {
+	if experience.Achievements == nil {
+		experience.Achievements = make(map[string]time.Time)
+	}
+	if experience.Rewarded == nil {
+		experience.Rewarded = make(map[string]bool)
+	}
+
+	levelBefore := Level(experience.XP)
+	var result Result
+
+	key := string(event.Type) + ":" + event.ID
+	if !experience.Rewarded[key] {
+		experience.Rewarded[key] = true
+		result.XP = XP(event.Type)
+		experience.XP += result.XP
+	}
+
+	for _, achievement := range Achievements {
+		if _, has := experience.Achievements[achievement.ID]; has || !achievement.unlocked(stats) {
+			continue
+		}
+		experience.Achievements[achievement.ID] = event.Time
+		result.Unlocked = append(result.Unlocked, achievement)
+	}
+
+	result.Level = Level(experience.XP)
+	result.LevelUp = result.Level > levelBefore
+	return result
+}
+
+// XP - сколько опыта приносит событие
+func XP(eventType EventType) int {
+	return xpRewards[eventType]
+}
+
+// Level - уровень по количеству опыта: 2-й уровень со 100 XP, 3-й с 300, 4-й с 600 и т.д.
+func Level(xp int) int {
+	level := 1
+	for xp >= LevelXP(level+1) {
+		level++
+	}
+	return level
+}
+
+// LevelXP - сколько опыта нужно для уровня
+func LevelXP(level int) int {
+	return 50 * level * (level - 1)
+}
@@ -0,0 +1,82 @@
+package gamification
+
+import (
+	"testing"
+	"time"
+
+	"proddy-bot/internal/models"
+)
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		xp   int
+		want int
+	}{
+		{0, 1},
+		{99, 1},
+		{100, 2},
+		{299, 2},
+		{300, 3},
+		{599, 3},
+		{600, 4},
+		{1000, 5},
+	}
+
+	for _, tt := range tests {
+		if got := Level(tt.xp); got != tt.want {
+			t.Errorf("Level(%d) = %d, want %d", tt.xp, got, tt.want)
+		}
+	}
+}
+
+func TestLevelXP(t *testing.T) {
+	tests := []struct {
+		level int
+		want  int
+	}{
+		{1, 0},
+		{2, 100},
+		{3, 300},
+		{4, 600},
+		{5, 1000},
+	}
+
+	for _, tt := range tests {
+		if got := LevelXP(tt.level); got != tt.want {
+			t.Errorf("LevelXP(%d) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2024, time.May, 15, 18, 0, 0, 0, time.UTC)
+	experience := &models.Experience{XP: 95}
+
+	result := Apply(experience, Event{Type: PomodoroCompleted, ID: "s1", Time: now}, Stats{Pomodoros: 1, PomodorosToday: 1})
+	if result.XP != 10 || experience.XP != 105 {
+		t.Errorf("first pomodoro: result XP = %d, total = %d, want 10 and 105", result.XP, experience.XP)
+	}
+	if !result.LevelUp || result.Level != 2 {
+		t.Errorf("first pomodoro: LevelUp = %v, Level = %d, want level up to 2", result.LevelUp, result.Level)
+	}
+	if len(result.Unlocked) != 1 || result.Unlocked[0].ID != "first_pomodoro" {
+		t.Errorf("first pomodoro: Unlocked = %v, want first_pomodoro", result.Unlocked)
+	}
+	if got := experience.Achievements["first_pomodoro"]; !got.Equal(now) {
+		t.Errorf("first_pomodoro unlocked at %v, want %v", got, now)
+	}
+
+	// То же событие повторно (например, после отмены) опыта и достижений не приносит
+	result = Apply(experience, Event{Type: PomodoroCompleted, ID: "s1", Time: now}, Stats{Pomodoros: 1, PomodorosToday: 1})
+	if result.XP != 0 || experience.XP != 105 || result.LevelUp || len(result.Unlocked) != 0 {
+		t.Errorf("repeated event: result = %+v, total XP = %d, want nothing new", result, experience.XP)
+	}
+
+	result = Apply(experience, Event{Type: GoalCompleted, ID: "g1", Time: now}, Stats{Pomodoros: 1, Goals: 1})
+	if result.XP != 50 || experience.XP != 155 || result.LevelUp {
+		t.Errorf("goal: result = %+v, total XP = %d, want +50 without level up", result, experience.XP)
+	}
+	if len(result.Unlocked) != 1 || result.Unlocked[0].ID != "first_goal" {
+		t.Errorf("goal: Unlocked = %v, want first_goal", result.Unlocked)
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/analytics"
+	"proddy-bot/internal/gamification"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+)
+
+// ========== ACHIEVEMENTS, XP AND LEVELS ==========
+
+// emitEvent начисляет опыт за событие и откладывает поздравления с новым уровнем и достижениями
+// до отправки ответа пользователю (см. flushRewards)
+func (h *Handler) emitEvent(userID string, eventType gamification.EventType, id string) {
+	now := time.Now()
+	experience, _ := h.storage.GetExperience(userID)
+	result := gamification.Apply(experience, gamification.Event{Type: eventType, ID: id, Time: now}, h.gameStats(userID, now))
+	if err := h.storage.SaveExperience(experience); err != nil {
+		return
+	}
+
+	if result.LevelUp {
+		h.rewards[userID] = append(h.rewards[userID], fmt.Sprintf("⭐ Новый уровень: %d! Всего опыта: %d XP", result.Level, experience.XP))
+	}
+	for _, achievement := range result.Unlocked {
+		h.rewards[userID] = append(h.rewards[userID], fmt.Sprintf("%s Новое достижение: «%s» - %s!", achievement.Icon, achievement.Title, achievement.Description))
+	}
+}
+
+// addRewards откладывает поздравления до следующего ответа пользователю
+func (h *Handler) addRewards(userID string, rewards ...string) {
+	if len(rewards) == 0 {
+		return
+	}
+	h.rewards[userID] = append(h.rewards[userID], rewards...)
+}
+
+// takeRewards забирает накопленные поздравления пользователя
+func (h *Handler) takeRewards(userID string) []string {
+	rewards := h.rewards[userID]
+	delete(h.rewards, userID)
+	return rewards
+}
+
+// flushRewards отправляет накопленные поздравления одним сообщением
+func (h *Handler) flushRewards(ctx context.Context, api *maxbot.Api, userID string, chatID int64) {
+	rewards := h.takeRewards(userID)
+	if len(rewards) == 0 {
+		return
+	}
+
+	text := strings.Join(rewards, "\n") + "\n\nВсе достижения: \"достижения\""
+	if _, err := api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(text)); sendError(err) != nil {
+		fmt.Printf("❌ Error sending rewards to %s: %v\n", userID, err)
+	}
+}
+
+// gameStats собирает показатели пользователя для проверки достижений
+func (h *Handler) gameStats(userID string, now time.Time) gamification.Stats {
+	var stats gamification.Stats
+
+	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
+	for _, session := range sessions {
+		if session.Type != "work" || !session.Completed {
+			continue
+		}
+		stats.Pomodoros++
+		if sameDay(session.StartTime, now) {
+			stats.PomodorosToday++
+		}
+	}
+	stats.FocusStreak = analytics.FocusStreak(sessions, now)
+
+	for _, task := range h.analyticsInput(userID).Tasks {
+		if !task.Completed {
+			continue
+		}
+		stats.Tasks++
+		if task.CompletedAt != nil && sameDay(*task.CompletedAt, now) {
+			stats.TasksToday++
+		}
+	}
+
+	goals, _ := h.storage.GetUserGoals(userID)
+	for _, goal := range goals {
+		if goal.Completed {
+			stats.Goals++
+		}
+	}
+	return stats
+}
+
+// getAchievements - уровень, опыт и список достижений
+func (h *Handler) getAchievements(userID string) string {
+	experience, _ := h.storage.GetExperience(userID)
+	level := gamification.Level(experience.XP)
+	from, to := gamification.LevelXP(level), gamification.LevelXP(level+1)
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("⭐ Уровень %d - %d XP\n", level, experience.XP))
+	response.WriteString(fmt.Sprintf("%s до %d уровня: %d XP\n\n", h.createProgressBar((experience.XP-from)*100/(to-from)), level+1, to-experience.XP))
+	response.WriteString(fmt.Sprintf("Опыт: 🍅 помодоро +%d, ✅ задача +%d, 🏆 цель +%d\n\n",
+		gamification.XP(gamification.PomodoroCompleted), gamification.XP(gamification.TaskCompleted), gamification.XP(gamification.GoalCompleted)))
+
+	response.WriteString(fmt.Sprintf("🏅 Достижения (%d из %d):\n", len(experience.Achievements), len(gamification.Achievements)))
+	for _, achievement := range gamification.Achievements {
+		if unlocked, has := experience.Achievements[achievement.ID]; has {
+			response.WriteString(fmt.Sprintf("%s %s - %s\n", achievement.Icon, achievement.Title, unlocked.Format("02.01.2006")))
+		} else {
+			response.WriteString(fmt.Sprintf("🔒 %s - %s\n", achievement.Title, achievement.Description))
+		}
+	}
+	return response.String()
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== TASK ARCHIVE FUNCTIONALITY ==========
+
+const archivePageSize = 10
+
+// archiveCommandPattern - команды архива: "архив", "архив задач 2", "восстановить задачу из архива 1",
+// "архивировать через 3 дня". Слово "архив" внутри текста задачи командой не считается.
+var archiveCommandPattern = regexp.MustCompile(`^(?:архив(?:\s+задач\S*)?(?:\s+\d+)?$|восстанов\S*\s+задач|архивировать\s+(?:через|сразу))`)
+
+func isArchiveCommand(text string) bool {
+	return archiveCommandPattern.MatchString(text)
+}
+
+func (h *Handler) handleArchiveCommand(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	switch {
+	case strings.HasPrefix(text, "восстанов"):
+		return h.restoreArchivedTask(text, userID)
+	case strings.HasPrefix(text, "архивировать"):
+		return h.setArchiveAfterDays(text, userID)
+	default:
+		page := 1
+		if _, after, found := strings.Cut(text, "архив"); found {
+			for _, field := range strings.Fields(after) {
+				if n, err := strconv.Atoi(field); err == nil && n > 0 {
+					page = n
+					break
+				}
+			}
+		}
+		h.sendArchivePage(ctx, api, userID, chatID, page)
+		return ""
+	}
+}
+
+func (h *Handler) handleArchiveCallback(ctx context.Context, api *maxbot.Api, upd *schemes.MessageCallbackUpdate, userID string, chatID int64) {
+	payload := upd.Callback.Payload
+
+	if strings.HasPrefix(payload, "archive_page_") {
+		page, err := strconv.Atoi(strings.TrimPrefix(payload, "archive_page_"))
+		if err != nil {
+			return
+		}
+		h.sendArchivePage(ctx, api, userID, chatID, page)
+	}
+}
+
+// archivedTasksNewestFirst возвращает архив, начиная с последних выполненных задач
+func (h *Handler) archivedTasksNewestFirst(userID string) []*models.Task {
+	archived, _ := h.storage.GetArchivedTasks(userID)
+	sorted := append([]*models.Task{}, archived...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return archiveTime(sorted[i]).After(archiveTime(sorted[j]))
+	})
+	return sorted
+}
+
+func archiveTime(task *models.Task) time.Time {
+	if task.CompletedAt != nil {
+		return *task.CompletedAt
+	}
+	if task.ArchivedAt != nil {
+		return *task.ArchivedAt
+	}
+	return task.Created
+}
+
+func (h *Handler) sendArchivePage(ctx context.Context, api *maxbot.Api, userID string, chatID int64, page int) {
+	archived := h.archivedTasksNewestFirst(userID)
+	if len(archived) == 0 {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("🗄 Архив задач пуст.\n\nВыполненные задачи попадают сюда автоматически."))
+		return
+	}
+
+	pages := (len(archived) + archivePageSize - 1) / archivePageSize
+	if page > pages {
+		page = pages
+	}
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * archivePageSize
+	end := start + archivePageSize
+	if end > len(archived) {
+		end = len(archived)
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🗄 Архив задач (стр. %d из %d, всего %d):\n\n", page, pages, len(archived)))
+	for i, task := range archived[start:end] {
+		response.WriteString(fmt.Sprintf("✅ %d. %s", start+i+1, task.Text))
+		if task.CompletedAt != nil {
+			response.WriteString(fmt.Sprintf(" (%s)", task.CompletedAt.Format("02.01.2006")))
+		}
+		response.WriteString("\n")
+	}
+	response.WriteString("\nВернуть задачу в работу: \"восстановить задачу из архива 1\"")
+
+	message := maxbot.NewMessage().SetChat(chatID).SetText(response.String())
+	if pages > 1 {
+		keyboard := api.Messages.NewKeyboardBuilder()
+		row := keyboard.AddRow()
+		if page > 1 {
+			row.AddCallback("◀ Назад", schemes.DEFAULT, fmt.Sprintf("archive_page_%d", page-1))
+		}
+		if page < pages {
+			row.AddCallback("Вперед ▶", schemes.DEFAULT, fmt.Sprintf("archive_page_%d", page+1))
+		}
+		message.AddKeyboard(keyboard)
+	}
+
+	if _, err := api.Messages.Send(ctx, message); sendError(err) != nil {
+		fmt.Printf("❌ Error sending message: %v\n", err)
+	}
+}
+
+// restoreArchivedTask возвращает задачу из архива в список как невыполненную
+func (h *Handler) restoreArchivedTask(text, userID string) string {
+	archived := h.archivedTasksNewestFirst(userID)
+	if len(archived) == 0 {
+		return "🗄 Архив задач пуст"
+	}
+
+	number, _ := extractNumber(text, len(archived))
+	if number == 0 {
+		return "❌ Укажи номер задачи из архива. Например: \"восстановить задачу из архива 1\""
+	}
+
+	task := archived[number-1]
+	if err := h.storage.RestoreTask(userID, task.ID); err != nil {
+		return "❌ Ошибка при восстановлении задачи"
+	}
+
+	task.Completed = false
+	task.CompletedAt = nil
+	if err := h.storage.UpdateTask(task); err != nil {
+		return "❌ Ошибка при восстановлении задачи"
+	}
+	h.syncGoalWithTasks(userID, task.GoalID)
+
+	return fmt.Sprintf("♻️ Задача возвращена в список: \"%s\"", task.Text)
+}
+
+func (h *Handler) setArchiveAfterDays(text, userID string) string {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+
+	days := -1
+	if strings.Contains(text, "сразу") {
+		days = 0
+	} else {
+		for _, field := range strings.Fields(text) {
+			if n, err := strconv.Atoi(field); err == nil && n >= 0 {
+				days = n
+				break
+			}
+		}
+	}
+	if days < 0 {
+		return "❌ Например: \"архивировать через 3 дня\" или \"архивировать сразу\""
+	}
+
+	data.Settings.ArchiveAfterDays = days
+	if err := h.storage.SaveUserData(data); err != nil {
+		return "❌ Ошибка при сохранении настроек"
+	}
+
+	if days == 0 {
+		return "✅ Выполненные задачи будут сразу уходить в архив"
+	}
+	return fmt.Sprintf("✅ Выполненные задачи будут уходить в архив через %d дн.", days)
+}
+
+// archiveCompletedTasks переносит в архив задачи, выполненные дольше настроенного срока
+func (h *Handler) archiveCompletedTasks(now time.Time) {
+	users, _ := h.storage.GetUsers()
+	for _, user := range users {
+		userID := user.MAXUserID
+
+		days := 7
+		if data, _ := h.storage.GetUserData(userID); data != nil {
+			days = data.Settings.ArchiveAfterDays
+		}
+
+		tasks, _ := h.storage.GetUserTasks(userID)
+		for _, task := range append([]*models.Task{}, tasks...) {
+			if !task.Completed || task.CompletedAt == nil {
+				continue
+			}
+			if now.Sub(*task.CompletedAt) >= time.Duration(days)*24*time.Hour {
+				h.storage.ArchiveTask(userID, task.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== TASK CHECKLIST FUNCTIONALITY ==========
+
+var (
+	addItemPattern  = regexp.MustCompile(`^добав\S*\s+пункт\S*\s+(?:к|в|для)\s+задач\S*\s+(\d+)\s+(.+)$`)
+	itemPattern     = regexp.MustCompile(`пункт\S*\s+(\d+)\s+(?:в\s+|из\s+|у\s+)?задач\S*\s+(\d+)`)
+	checklistTaskRe = regexp.MustCompile(`задач\S*\s+(\d+)`)
+)
+
+func isChecklistCommand(text string) bool {
+	return strings.Contains(text, "чеклист") || addItemPattern.MatchString(text) || itemPattern.MatchString(text)
+}
+
+func (h *Handler) handleChecklistCommand(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	switch {
+	case strings.HasPrefix(text, "добав"):
+		return h.addChecklistItems(text, userID)
+	case strings.HasPrefix(text, "удали"):
+		return h.deleteChecklistItem(text, userID)
+	case strings.HasPrefix(text, "отмет") || strings.HasPrefix(text, "выполни"):
+		return h.toggleChecklistItem(ctx, api, text, userID, chatID)
+	default:
+		return h.showChecklist(ctx, api, text, userID, chatID)
+	}
+}
+
+// addChecklistItems добавляет один или несколько пунктов (через запятую) в задачу
+func (h *Handler) addChecklistItems(text, userID string) string {
+	match := addItemPattern.FindStringSubmatch(text)
+	if match == nil {
+		return "❌ Например: \"добавить пункт к задаче 1 купить молоко\" (несколько пунктов - через запятую)"
+	}
+
+	task, ok := h.taskByNumber(userID, match[1])
+	if !ok {
+		return "❌ Задача с таким номером не найдена"
+	}
+
+	added := 0
+	for _, itemText := range strings.Split(match[2], ",") {
+		itemText = strings.TrimSpace(itemText)
+		if itemText == "" {
+			continue
+		}
+		task.Items = append(task.Items, models.ChecklistItem{
+			ID:   newID(),
+			Text: itemText,
+		})
+		added++
+	}
+	if added == 0 {
+		return "❌ Текст пункта не может быть пустым"
+	}
+
+	// Новый невыполненный пункт снова открывает задачу
+	task.Completed = false
+	task.CompletedAt = nil
+
+	if err := h.storage.UpdateTask(task); err != nil {
+		return "❌ Ошибка при добавлении пункта"
+	}
+
+	done, total := checklistProgress(task)
+	return fmt.Sprintf("✅ Добавлено пунктов: %d\n☑️ \"%s\": %d/%d\n\nИспользуй \"чеклист задачи %s\" чтобы отмечать пункты кнопками.",
+		added, task.Text, done, total, match[1])
+}
+
+func (h *Handler) deleteChecklistItem(text, userID string) string {
+	task, index, ok := h.checklistItemFromText(text, userID)
+	if !ok {
+		return "❌ Например: \"удалить пункт 2 задачи 1\""
+	}
+
+	removed := task.Items[index]
+	task.Items = append(task.Items[:index], task.Items[index+1:]...)
+	wasCompleted := task.Completed
+	h.syncChecklistCompletion(task)
+
+	// Удаление последнего невыполненного пункта завершает задачу так же, как его отметка
+	if task.Completed && !wasCompleted {
+		_, achieved, err := h.markTaskCompleted(task)
+		if err != nil {
+			return "❌ Ошибка при удалении пункта"
+		}
+		response := fmt.Sprintf("🗑 Пункт удален: \"%s\"\n🎉 Остальные пункты отмечены - задача \"%s\" выполнена!", removed.Text, task.Text)
+		if achieved != nil {
+			response += "\n\n" + h.goalCompletedMessage(achieved)
+		}
+		return response
+	}
+
+	if err := h.storage.UpdateTask(task); err != nil {
+		return "❌ Ошибка при удалении пункта"
+	}
+
+	return fmt.Sprintf("🗑 Пункт удален: \"%s\"", removed.Text)
+}
+
+func (h *Handler) toggleChecklistItem(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	task, index, ok := h.checklistItemFromText(text, userID)
+	if !ok {
+		return "❌ Например: \"отметить пункт 2 задачи 1\""
+	}
+
+	h.applyChecklistToggle(ctx, api, task, index, chatID)
+	return ""
+}
+
+func (h *Handler) showChecklist(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	match := checklistTaskRe.FindStringSubmatch(text)
+	if match == nil {
+		return "❌ Укажи номер задачи. Например: \"чеклист задачи 1\""
+	}
+
+	task, ok := h.taskByNumber(userID, match[1])
+	if !ok {
+		return "❌ Задача с таким номером не найдена"
+	}
+	if len(task.Items) == 0 {
+		return fmt.Sprintf("☑️ В задаче \"%s\" пока нет пунктов.\n\nДобавь их: \"добавить пункт к задаче %s [текст]\"", task.Text, match[1])
+	}
+
+	h.sendChecklist(ctx, api, task, chatID)
+	return ""
+}
+
+func (h *Handler) toggleChecklistItemByID(ctx context.Context, api *maxbot.Api, userID string, chatID int64, payload string) {
+	ids := strings.SplitN(payload, "_", 2)
+	if len(ids) != 2 {
+		return
+	}
+
+	tasks, _ := h.storage.GetUserTasks(userID)
+	for _, task := range tasks {
+		if task.ID != ids[0] {
+			continue
+		}
+		for i, item := range task.Items {
+			if item.ID == ids[1] {
+				h.applyChecklistToggle(ctx, api, task, i, chatID)
+				return
+			}
+		}
+	}
+}
+
+// applyChecklistToggle переключает пункт и завершает задачу, когда отмечены все пункты
+func (h *Handler) applyChecklistToggle(ctx context.Context, api *maxbot.Api, task *models.Task, index int, chatID int64) {
+	task.Items[index].Completed = !task.Items[index].Completed
+	wasCompleted := task.Completed
+	h.syncChecklistCompletion(task)
+
+	var err error
+	var achieved *models.Goal
+	if task.Completed && !wasCompleted {
+		_, achieved, err = h.markTaskCompleted(task)
+	} else {
+		err = h.storage.UpdateTask(task)
+		if err == nil && wasCompleted {
+			h.syncGoalWithTasks(task.UserID, task.GoalID)
+		}
+	}
+	if err != nil {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("❌ Ошибка при обновлении пункта"))
+		return
+	}
+
+	if task.Completed && !wasCompleted {
+		response := fmt.Sprintf("🎉 Все пункты отмечены - задача \"%s\" выполнена!", task.Text)
+		if achieved != nil {
+			response += "\n\n" + h.goalCompletedMessage(achieved)
+		}
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+		return
+	}
+
+	h.sendChecklist(ctx, api, task, chatID)
+}
+
+// syncChecklistCompletion выставляет статус задачи по ее пунктам
+func (h *Handler) syncChecklistCompletion(task *models.Task) {
+	if len(task.Items) == 0 {
+		return
+	}
+	done, total := checklistProgress(task)
+	task.Completed = done == total
+	if !task.Completed {
+		task.CompletedAt = nil
+	} else if task.CompletedAt == nil {
+		now := time.Now()
+		task.CompletedAt = &now
+	}
+}
+
+func (h *Handler) sendChecklist(ctx context.Context, api *maxbot.Api, task *models.Task, chatID int64) {
+	done, total := checklistProgress(task)
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("☑️ %s (%d/%d)\n\n", task.Text, done, total))
+
+	keyboard := api.Messages.NewKeyboardBuilder()
+	for i, item := range task.Items {
+		mark, intent := "⬜", schemes.DEFAULT
+		if item.Completed {
+			mark, intent = "✅", schemes.POSITIVE
+		}
+		response.WriteString(fmt.Sprintf("%s %d. %s\n", mark, i+1, item.Text))
+		keyboard.AddRow().AddCallback(fmt.Sprintf("%s %s", mark, item.Text), intent, fmt.Sprintf("task_item_%s_%s", task.ID, item.ID))
+	}
+	response.WriteString("\nНажми на пункт чтобы отметить его")
+
+	_, err := api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response.String()).AddKeyboard(keyboard))
+	if err = sendError(err); err != nil {
+		fmt.Printf("❌ Error sending message: %v\n", err)
+	}
+}
+
+// checklistItemFromText находит задачу и индекс пункта по тексту вида "пункт 2 задачи 1"
+func (h *Handler) checklistItemFromText(text, userID string) (*models.Task, int, bool) {
+	match := itemPattern.FindStringSubmatch(text)
+	if match == nil {
+		return nil, 0, false
+	}
+
+	task, ok := h.taskByNumber(userID, match[2])
+	if !ok {
+		return nil, 0, false
+	}
+
+	itemNumber, err := strconv.Atoi(match[1])
+	if err != nil || itemNumber < 1 || itemNumber > len(task.Items) {
+		return nil, 0, false
+	}
+	return task, itemNumber - 1, true
+}
+
+// taskByNumber возвращает задачу по ее номеру в списке
+func (h *Handler) taskByNumber(userID, number string) (*models.Task, bool) {
+	tasks, _ := h.storage.GetUserTasks(userID)
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 || n > len(tasks) {
+		return nil, false
+	}
+	return tasks[n-1], true
+}
+
+func checklistProgress(task *models.Task) (int, int) {
+	done := 0
+	for _, item := range task.Items {
+		if item.Completed {
+			done++
+		}
+	}
+	return done, len(task.Items)
+}
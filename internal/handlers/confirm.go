@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== BULK ACTIONS WITH CONFIRMATION ==========
+
+// confirmationTTL - сколько живет кнопка подтверждения массового действия
+const confirmationTTL = 2 * time.Minute
+
+// confirmation - массовое действие, ожидающее подтверждения кнопкой
+type confirmation struct {
+	userID  string
+	expires time.Time
+	action  func(ctx context.Context, api *maxbot.Api, chatID int64)
+}
+
+var bulkCommands = []string{
+	"удалить выполненные задачи",
+	"очистить выполненные задачи",
+	"удалить все задачи",
+	"очистить все задачи",
+	"очистить задачи",
+	"удалить все цели",
+	"очистить все цели",
+	"очистить цели",
+}
+
+func isBulkCommand(text string) bool {
+	for _, command := range bulkCommands {
+		if strings.HasPrefix(text, command) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) handleBulkCommand(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	switch {
+	case strings.Contains(text, "выполненные"):
+		return h.confirmDeleteTasks(ctx, api, userID, chatID, true)
+	case strings.Contains(text, "задачи"):
+		return h.confirmDeleteTasks(ctx, api, userID, chatID, false)
+	default:
+		return h.confirmDeleteGoals(ctx, api, userID, chatID)
+	}
+}
+
+func (h *Handler) confirmDeleteTasks(ctx context.Context, api *maxbot.Api, userID string, chatID int64, onlyCompleted bool) string {
+	tasks, _ := h.storage.GetUserTasks(userID)
+
+	var taskIDs []string
+	for _, task := range tasks {
+		if !onlyCompleted || task.Completed {
+			taskIDs = append(taskIDs, task.ID)
+		}
+	}
+	if len(taskIDs) == 0 {
+		if onlyCompleted {
+			return "📝 Выполненных задач нет - удалять нечего"
+		}
+		return "📝 У тебя пока нет задач!"
+	}
+
+	question := fmt.Sprintf("⚠️ Удалить все задачи (%d шт.)?", len(taskIDs))
+	if onlyCompleted {
+		question = fmt.Sprintf("⚠️ Удалить выполненные задачи (%d шт.)?", len(taskIDs))
+	}
+
+	h.askConfirmation(ctx, api, userID, chatID, question, func(ctx context.Context, api *maxbot.Api, chatID int64) {
+		for _, taskID := range taskIDs {
+			h.storage.DeleteTask(userID, taskID)
+		}
+		h.syncUserGoals(userID)
+		token := h.pushUndo(userID, "массовое удаление задач", func(ctx context.Context, api *maxbot.Api, chatID int64) string {
+			restored := 0
+			for _, taskID := range taskIDs {
+				if h.storage.UndeleteTask(userID, taskID) == nil {
+					restored++
+				}
+			}
+			h.syncUserGoals(userID)
+			return fmt.Sprintf("↩ Восстановлено задач: %d", restored)
+		})
+		h.sendWithUndo(ctx, api, chatID, fmt.Sprintf("🗑 Удалено задач: %d", len(taskIDs)), token)
+	})
+	return ""
+}
+
+func (h *Handler) confirmDeleteGoals(ctx context.Context, api *maxbot.Api, userID string, chatID int64) string {
+	goals, _ := h.storage.GetUserGoals(userID)
+	if len(goals) == 0 {
+		return "🎯 У тебя пока нет целей!"
+	}
+
+	goalIDs := make([]string, 0, len(goals))
+	for _, goal := range goals {
+		goalIDs = append(goalIDs, goal.ID)
+	}
+
+	question := fmt.Sprintf("⚠️ Удалить все цели (%d шт.)?", len(goalIDs))
+	h.askConfirmation(ctx, api, userID, chatID, question, func(ctx context.Context, api *maxbot.Api, chatID int64) {
+		for _, goalID := range goalIDs {
+			h.storage.DeleteGoal(userID, goalID)
+		}
+		token := h.pushUndo(userID, "массовое удаление целей", func(ctx context.Context, api *maxbot.Api, chatID int64) string {
+			restored := 0
+			for _, goalID := range goalIDs {
+				if h.storage.UndeleteGoal(userID, goalID) == nil {
+					restored++
+				}
+			}
+			return fmt.Sprintf("↩ Восстановлено целей: %d", restored)
+		})
+		h.sendWithUndo(ctx, api, chatID, fmt.Sprintf("🗑 Удалено целей: %d", len(goalIDs)), token)
+	})
+	return ""
+}
+
+// askConfirmation отправляет вопрос с кнопками "Да"/"Нет"; действие выполнится только по кнопке "Да"
+func (h *Handler) askConfirmation(ctx context.Context, api *maxbot.Api, userID string, chatID int64, question string, action func(ctx context.Context, api *maxbot.Api, chatID int64)) {
+	now := time.Now()
+	for token, pending := range h.confirmations {
+		if now.After(pending.expires) {
+			delete(h.confirmations, token)
+		}
+	}
+
+	token := newConfirmationToken()
+	h.confirmations[token] = &confirmation{
+		userID:  userID,
+		expires: now.Add(confirmationTTL),
+		action:  action,
+	}
+
+	keyboard := api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback("✅ Да, удалить", schemes.NEGATIVE, "confirm_yes_"+token).
+		AddCallback("✖️ Нет", schemes.DEFAULT, "confirm_no_"+token)
+
+	text := fmt.Sprintf("%s\n\nКнопка действует %d мин.", question, int(confirmationTTL/time.Minute))
+	message := maxbot.NewMessage().SetChat(chatID).SetText(text).AddKeyboard(keyboard)
+	if _, err := api.Messages.Send(ctx, message); sendError(err) != nil {
+		fmt.Printf("❌ Error sending message: %v\n", err)
+	}
+}
+
+func (h *Handler) handleConfirmCallback(ctx context.Context, api *maxbot.Api, upd *schemes.MessageCallbackUpdate, userID string, chatID int64) {
+	payload := strings.TrimPrefix(upd.Callback.Payload, "confirm_")
+	answer, token, found := strings.Cut(payload, "_")
+	if !found {
+		return
+	}
+
+	pending, exists := h.confirmations[token]
+	if !exists || pending.userID != userID {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("⌛ Это подтверждение уже недействительно"))
+		return
+	}
+	delete(h.confirmations, token)
+
+	switch {
+	case time.Now().After(pending.expires):
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("⌛ Время на подтверждение истекло - повтори команду"))
+	case answer == "yes":
+		pending.action(ctx, api, chatID)
+	default:
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("👌 Отменено, ничего не удалено"))
+	}
+}
+
+func newConfirmationToken() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return newID()
+	}
+	return hex.EncodeToString(buf)
+}
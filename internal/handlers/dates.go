@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// endOfDay - время по умолчанию для сроков, указанных без часов
+const endOfDay = 23*time.Hour + 59*time.Minute
+
+var weekdayNames = map[string]time.Weekday{
+	"понедельник": time.Monday, "пн": time.Monday,
+	"вторник": time.Tuesday, "вт": time.Tuesday,
+	"среда": time.Wednesday, "среду": time.Wednesday, "ср": time.Wednesday,
+	"четверг": time.Thursday, "чт": time.Thursday,
+	"пятница": time.Friday, "пятницу": time.Friday, "пт": time.Friday,
+	"суббота": time.Saturday, "субботу": time.Saturday, "сб": time.Saturday,
+	"воскресенье": time.Sunday, "вс": time.Sunday,
+}
+
+// parseWhen разбирает дату и время в начале строки ("завтра 18:00", "25.12",
+// "через 2 часа", "в пятницу") и возвращает момент и оставшийся текст.
+// Если время не указано, берется defaultTime от начала найденного дня.
+func parseWhen(text string, now time.Time, defaultTime time.Duration) (time.Time, string, bool) {
+	fields := strings.Fields(text)
+	i := 0
+	next := func() string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// "через 2 часа", "через час"
+	if next() == "через" {
+		i++
+		amount := 1
+		if n, err := strconv.Atoi(next()); err == nil && n > 0 {
+			amount = n
+			i++
+		}
+		unit := next()
+		var d time.Duration
+		switch {
+		case strings.HasPrefix(unit, "мин"):
+			d = time.Duration(amount) * time.Minute
+		case strings.HasPrefix(unit, "час"):
+			d = time.Duration(amount) * time.Hour
+		case strings.HasPrefix(unit, "дн") || strings.HasPrefix(unit, "день"):
+			return today.AddDate(0, 0, amount).Add(defaultTime), strings.Join(fields[i+1:], " "), true
+		case strings.HasPrefix(unit, "недел"):
+			return today.AddDate(0, 0, 7*amount).Add(defaultTime), strings.Join(fields[i+1:], " "), true
+		default:
+			return time.Time{}, text, false
+		}
+		return now.Add(d), strings.Join(fields[i+1:], " "), true
+	}
+
+	var day time.Time
+	dateFound := false
+
+	if next() == "в" || next() == "во" {
+		if _, ok := weekdayNames[nextField(fields, i+1)]; ok {
+			i++
+		}
+	}
+
+	word := next()
+	switch {
+	case word == "сегодня":
+		day, dateFound = today, true
+	case word == "завтра":
+		day, dateFound = today.AddDate(0, 0, 1), true
+	case word == "послезавтра":
+		day, dateFound = today.AddDate(0, 0, 2), true
+	default:
+		if wd, ok := weekdayNames[word]; ok {
+			ahead := (int(wd) - int(now.Weekday()) + 7) % 7
+			if ahead == 0 {
+				ahead = 7
+			}
+			day, dateFound = today.AddDate(0, 0, ahead), true
+		} else if d, ok := parseDayMonth(word, now); ok {
+			day, dateFound = d, true
+		}
+	}
+	if dateFound {
+		i++
+	}
+
+	// Время: "18:00" или "в 18:00"
+	clock, clockFound := time.Duration(0), false
+	if next() == "в" {
+		if c, ok := parseClock(nextField(fields, i+1)); ok {
+			clock, clockFound = c, true
+			i += 2
+		}
+	} else if c, ok := parseClock(next()); ok {
+		clock, clockFound = c, true
+		i++
+	}
+
+	rest := strings.Join(fields[i:], " ")
+	switch {
+	case dateFound && clockFound:
+		return day.Add(clock), rest, true
+	case dateFound:
+		return day.Add(defaultTime), rest, true
+	case clockFound:
+		t := today.Add(clock)
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, rest, true
+	}
+	return time.Time{}, text, false
+}
+
+func nextField(fields []string, i int) string {
+	if i < len(fields) {
+		return fields[i]
+	}
+	return ""
+}
+
+// parseDayMonth разбирает "25.12" и "25.12.2025"
+func parseDayMonth(s string, now time.Time) (time.Time, bool) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return time.Time{}, false
+	}
+	day, err1 := strconv.Atoi(parts[0])
+	month, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || day < 1 || day > 31 || month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	year := now.Year()
+	explicitYear := len(parts) == 3
+	if explicitYear {
+		y, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return time.Time{}, false
+		}
+		if y < 100 {
+			y += 2000
+		}
+		year = y
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, now.Location())
+	if t.Day() != day {
+		return time.Time{}, false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !explicitYear && t.Before(today) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return t, true
+}
+
+// parseClock разбирает время вида "18:00"
+func parseClock(s string) (time.Duration, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, false
+	}
+	hour, err1 := strconv.Atoi(parts[0])
+	minute, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, false
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true
+}
+
+// formatWhen форматирует срок для вывода пользователю
+func formatWhen(t time.Time) string {
+	layout := "02.01"
+	if t.Year() != time.Now().Year() {
+		layout = "02.01.2006"
+	}
+	if t.Hour() != 23 || t.Minute() != 59 {
+		layout += " 15:04"
+	}
+	return t.Format(layout)
+}
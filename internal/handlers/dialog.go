@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== MULTI-STEP DIALOGS ==========
+
+// dialogTimeout - через сколько минут без ответа диалог сбрасывается
+const dialogTimeout = 10 * time.Minute
+
+// skipAnswer - ответ, которым можно пропустить необязательный шаг
+const skipAnswer = "пропустить"
+
+// dialogEscapeCommands - команды, которые прерывают открытый диалог и выполняются как обычно.
+// Сравниваются целиком, чтобы не перехватывать ответы вроде "подготовить меню на неделю".
+var dialogEscapeCommands = map[string]bool{
+	"/start":         true,
+	"меню":           true,
+	"помощь":         true,
+	"задачи":         true,
+	"цели":           true,
+	"статистика":     true,
+	"старт помодоро": true,
+	"стоп помодоро":  true,
+	"перерыв":        true,
+}
+
+// dialogStep - один вопрос диалога. parse проверяет ответ и возвращает значение
+// для сохранения либо текст ошибки.
+type dialogStep struct {
+	key      string
+	question string
+	options  []string
+	optional bool
+	parse    func(answer string, now time.Time) (string, string)
+}
+
+// dialogFlow - последовательность вопросов и действие после последнего ответа
+type dialogFlow struct {
+	steps  []dialogStep
+	finish func(h *Handler, state *models.DialogState) string
+}
+
+var dialogFlows = map[string]dialogFlow{
+	"add_task": {
+		steps: []dialogStep{
+			{key: "text", question: "📝 Что нужно сделать?", parse: parseDialogText},
+			{
+				key:      "deadline",
+				question: "⏰ Какой срок? Например: \"завтра 18:00\", \"пт\", \"25.12\"",
+				options:  []string{"Сегодня", "Завтра", "Пропустить"},
+				optional: true,
+				parse:    parseDialogDeadline,
+			},
+			{
+				key:      "priority",
+				question: "❗ Какой приоритет?",
+				options:  []string{"Высокий", "Средний", "Низкий"},
+				optional: true,
+				parse:    parseDialogPriority,
+			},
+		},
+		finish: (*Handler).finishAddTask,
+	},
+	"add_goal": {
+		steps: []dialogStep{
+			{key: "title", question: "🎯 Какую цель поставим?", parse: parseDialogText},
+			{
+				key:      "deadline",
+				question: "⏰ К какому сроку? Например: \"31.12\" или \"через 2 недели\". По умолчанию - через месяц.",
+				options:  []string{"Пропустить"},
+				optional: true,
+				parse:    parseDialogDeadline,
+			},
+		},
+		finish: (*Handler).finishAddGoal,
+	},
+	"settings": {
+		steps: []dialogStep{
+			{
+				key:      "work",
+				question: "🍅 Сколько минут длится Pomodoro-сессия?",
+				options:  []string{"25", "50", "Пропустить"},
+				optional: true,
+				parse:    parseDialogMinutes(1, 180),
+			},
+			{
+				key:      "break",
+				question: "☕ Сколько минут длится перерыв?",
+				options:  []string{"5", "10", "Пропустить"},
+				optional: true,
+				parse:    parseDialogMinutes(1, 60),
+			},
+			{
+				key:      "lead",
+				question: "⏳ За сколько минут до срока напоминать о задаче?",
+				options:  []string{"30", "60", "Пропустить"},
+				optional: true,
+				parse:    parseDialogMinutes(1, 7*24*60),
+			},
+		},
+		finish: (*Handler).finishSettings,
+	},
+}
+
+// startDialog начинает диалог и задает первый вопрос
+func (h *Handler) startDialog(ctx context.Context, api *maxbot.Api, flow, userID string, chatID int64) string {
+	state := &models.DialogState{
+		UserID: userID,
+		Flow:   flow,
+		Data:   map[string]string{},
+	}
+	if err := h.storage.SaveDialogState(state); err != nil {
+		return "❌ Ошибка при сохранении диалога"
+	}
+
+	h.askDialogStep(ctx, api, state, chatID, "")
+	return ""
+}
+
+// handleDialogMessage обрабатывает сообщение, если у пользователя открыт диалог.
+// Второе значение - было ли сообщение частью диалога.
+func (h *Handler) handleDialogMessage(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) (string, bool) {
+	state, err := h.storage.GetDialogState(userID)
+	if err != nil {
+		return "", false
+	}
+
+	flow, exists := dialogFlows[state.Flow]
+	if !exists || state.Step >= len(flow.steps) {
+		h.storage.DeleteDialogState(userID)
+		return "", false
+	}
+
+	if time.Since(state.Updated) > dialogTimeout {
+		// Диалог устарел - предупреждаем и обрабатываем сообщение как обычную команду
+		h.storage.DeleteDialogState(userID)
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("⌛ Предыдущий диалог прерван: ответа не было больше 10 минут"))
+		return "", false
+	}
+
+	if dialogEscapeCommands[text] {
+		// Пользователь ушел из диалога - выполняем команду как обычно
+		h.storage.DeleteDialogState(userID)
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("👌 Предыдущий диалог отменен, ничего не сохранено"))
+		return "", false
+	}
+
+	if text == "/cancel" || text == "отмена" || text == "отменить" {
+		h.storage.DeleteDialogState(userID)
+		return "👌 Хорошо, отменили. Ничего не сохранено.", true
+	}
+
+	step := flow.steps[state.Step]
+	if step.optional && (text == skipAnswer || text == "-") {
+		delete(state.Data, step.key)
+	} else {
+		value, problem := step.parse(text, h.userNow(userID))
+		if problem != "" {
+			h.askDialogStep(ctx, api, state, chatID, problem)
+			return "", true
+		}
+		state.Data[step.key] = value
+	}
+
+	state.Step++
+	if state.Step < len(flow.steps) {
+		h.storage.SaveDialogState(state)
+		h.askDialogStep(ctx, api, state, chatID, "")
+		return "", true
+	}
+
+	h.storage.DeleteDialogState(userID)
+	return flow.finish(h, state), true
+}
+
+func (h *Handler) handleDialogCallback(ctx context.Context, api *maxbot.Api, upd *schemes.MessageCallbackUpdate, userID string, chatID int64) {
+	// Кнопка закрытого или устаревшего диалога - достаточно одного сообщения
+	if state, err := h.storage.GetDialogState(userID); err != nil || time.Since(state.Updated) > dialogTimeout {
+		h.storage.DeleteDialogState(userID)
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("⌛ Этот вопрос уже неактуален"))
+		return
+	}
+
+	answer := strings.TrimPrefix(upd.Callback.Payload, "dialog_")
+	response, handled := h.handleDialogMessage(ctx, api, answer, userID, chatID)
+	if !handled {
+		response = "⌛ Этот вопрос уже неактуален"
+	}
+	if response != "" {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+	}
+}
+
+// askDialogStep задает текущий вопрос диалога; problem - пояснение к неверному ответу
+func (h *Handler) askDialogStep(ctx context.Context, api *maxbot.Api, state *models.DialogState, chatID int64, problem string) {
+	step := dialogFlows[state.Flow].steps[state.Step]
+
+	text := step.question
+	if problem != "" {
+		text = problem + "\n\n" + text
+	}
+	if step.optional {
+		text += "\n\n\"пропустить\" - оставить по умолчанию"
+	}
+	text += "\n/cancel - отменить"
+
+	message := maxbot.NewMessage().SetChat(chatID).SetText(text)
+	if len(step.options) > 0 {
+		keyboard := api.Messages.NewKeyboardBuilder()
+		row := keyboard.AddRow()
+		for _, option := range step.options {
+			row.AddCallback(option, schemes.DEFAULT, "dialog_"+strings.ToLower(option))
+		}
+		row.AddCallback("✖️ Отмена", schemes.NEGATIVE, "dialog_/cancel")
+		message.AddKeyboard(keyboard)
+	}
+
+	if _, err := api.Messages.Send(ctx, message); sendError(err) != nil {
+		fmt.Printf("❌ Error sending message: %v\n", err)
+	}
+}
+
+func parseDialogText(answer string, now time.Time) (string, string) {
+	if answer == "" {
+		return "", "❌ Ответ не может быть пустым"
+	}
+	return answer, ""
+}
+
+func parseDialogDeadline(answer string, now time.Time) (string, string) {
+	deadline, rest, ok := parseWhen(answer, now, endOfDay)
+	if !ok || rest != "" {
+		return "", "❌ Не понял срок"
+	}
+	if deadline.Before(now) {
+		return "", "❌ Этот срок уже прошел"
+	}
+	return deadline.Format(time.RFC3339), ""
+}
+
+func parseDialogPriority(answer string, now time.Time) (string, string) {
+	priority, ok := priorityNames[answer]
+	if !ok {
+		return "", "❌ Приоритет может быть: высокий, средний, низкий"
+	}
+	return priority, ""
+}
+
+func parseDialogMinutes(min, max int) func(answer string, now time.Time) (string, string) {
+	return func(answer string, now time.Time) (string, string) {
+		fields := strings.Fields(answer)
+		if len(fields) == 0 {
+			return "", "❌ Укажи число минут"
+		}
+		minutes, err := strconv.Atoi(fields[0])
+		if err != nil || minutes < min || minutes > max {
+			return "", fmt.Sprintf("❌ Укажи число минут от %d до %d", min, max)
+		}
+		return strconv.Itoa(minutes), ""
+	}
+}
+
+// dialogDeadline возвращает срок, сохраненный в диалоге
+func dialogDeadline(state *models.DialogState) (time.Time, bool) {
+	value, exists := state.Data["deadline"]
+	if !exists {
+		return time.Time{}, false
+	}
+	deadline, err := time.Parse(time.RFC3339, value)
+	return deadline, err == nil
+}
+
+func (h *Handler) finishAddTask(state *models.DialogState) string {
+	task := newTask(state.UserID, state.Data["text"], h.userNow(state.UserID))
+	if deadline, ok := dialogDeadline(state); ok && task.Recurrence == nil {
+		task.Deadline = &deadline
+	}
+	if priority, exists := state.Data["priority"]; exists {
+		task.Priority = priority
+	}
+
+	if err := h.storage.SaveTask(task); err != nil {
+		return "❌ Ошибка при добавлении задачи"
+	}
+	return taskAddedMessage(task)
+}
+
+func (h *Handler) finishAddGoal(state *models.DialogState) string {
+	goal := newGoal(state.UserID, state.Data["title"])
+	if deadline, ok := dialogDeadline(state); ok {
+		goal.Deadline = deadline
+	}
+
+	if err := h.storage.SaveGoal(goal); err != nil {
+		return "❌ Ошибка при добавлении цели"
+	}
+	return fmt.Sprintf("✅ Цель добавлена: \"%s\"\n⏰ Срок: %s\n\nИспользуй \"список целей\" чтобы посмотреть все цели.",
+		goal.Title, formatWhen(goal.Deadline))
+}
+
+func (h *Handler) finishSettings(state *models.DialogState) string {
+	data, _ := h.storage.GetUserData(state.UserID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+
+	if value, exists := state.Data["work"]; exists {
+		data.Settings.PomodoroWorkDuration, _ = strconv.Atoi(value)
+	}
+	if value, exists := state.Data["break"]; exists {
+		data.Settings.PomodoroBreakDuration, _ = strconv.Atoi(value)
+	}
+	if value, exists := state.Data["lead"]; exists {
+		data.Settings.ReminderLeadTime, _ = strconv.Atoi(value)
+	}
+
+	if err := h.storage.SaveUserData(data); err != nil {
+		return "❌ Ошибка при сохранении настроек"
+	}
+	return "✅ Настройки сохранены\n\n" + h.describeSettings(state.UserID)
+}
+
+// describeSettings выводит текущие настройки пользователя
+func (h *Handler) describeSettings(userID string) string {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+	return fmt.Sprintf("⚙️ Настройки:\n• Pomodoro: %d мин.\n• Перерыв: %d мин.\n• Напоминание о сроке: за %s",
+		data.Settings.PomodoroWorkDuration, data.Settings.PomodoroBreakDuration, formatMinutes(data.Settings.ReminderLeadTime))
+}
+
+// pomodoroDurations возвращает длительность сессии и перерыва из настроек пользователя
+func (h *Handler) pomodoroDurations(userID string) (int, int) {
+	work, rest := 25, 5
+	if data, _ := h.storage.GetUserData(userID); data != nil {
+		if data.Settings.PomodoroWorkDuration > 0 {
+			work = data.Settings.PomodoroWorkDuration
+		}
+		if data.Settings.PomodoroBreakDuration > 0 {
+			rest = data.Settings.PomodoroBreakDuration
+		}
+	}
+	return work, rest
+}
@@ -0,0 +1,442 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== MORNING DIGEST AND EVENING REVIEW ==========
+
+// reviewButtonsLimit - для скольких задач в вечернем итоге показывать кнопки переноса
+const reviewButtonsLimit = 5
+
+var (
+	digestPattern        = regexp.MustCompile(`^(утренн\S*\s+сводк\S*|вечерн\S*\s+итог\S*)(?:\s+в)?(?:\s+(\d{1,2}:\d{2}))?$`)
+	digestDisablePattern = regexp.MustCompile(`^(?:отключ|выключ)\S*\s+(утренн\S*\s+сводк\S*|вечерн\S*\s+итог\S*)$`)
+	timezonePattern      = regexp.MustCompile(`^часов\S*\s+пояс\S*\s*(.*)$`)
+	utcOffsetPattern     = regexp.MustCompile(`^([+-]?)\s*(\d{1,2})(?::(\d{2}))?$`)
+)
+
+func isDigestCommand(text string) bool {
+	return digestPattern.MatchString(text) || digestDisablePattern.MatchString(text) || timezonePattern.MatchString(text)
+}
+
+func (h *Handler) handleDigestCommand(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	if match := timezonePattern.FindStringSubmatch(text); match != nil {
+		return h.setTimezone(strings.TrimSpace(match[1]), userID)
+	}
+
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+
+	if match := digestDisablePattern.FindStringSubmatch(text); match != nil {
+		if strings.HasPrefix(match[1], "утренн") {
+			data.Settings.MorningDigestAt = ""
+		} else {
+			data.Settings.EveningReviewAt = ""
+		}
+		if err := h.storage.SaveUserData(data); err != nil {
+			return "❌ Ошибка при сохранении настроек"
+		}
+		return "🔕 Больше не буду присылать " + digestTitle(match[1])
+	}
+
+	match := digestPattern.FindStringSubmatch(text)
+	morning := strings.HasPrefix(match[1], "утренн")
+	now := h.userNow(userID)
+
+	if match[2] == "" {
+		// Без времени - показываем сводку прямо сейчас
+		if morning {
+			return h.morningDigest(userID, now) + "\n\n" + digestSchedule(data.Settings.MorningDigestAt, "утренняя сводка в 8:00")
+		}
+		review, keyboard := h.eveningReview(api, userID, now)
+		message := maxbot.NewMessage().SetChat(chatID).SetText(review + "\n\n" + digestSchedule(data.Settings.EveningReviewAt, "вечерний итог в 21:00"))
+		if keyboard != nil {
+			message.AddKeyboard(keyboard)
+		}
+		if _, err := api.Messages.Send(ctx, message); sendError(err) != nil {
+			return "❌ Ошибка при отправке итога"
+		}
+		return ""
+	}
+
+	clock, ok := parseClock(match[2])
+	if !ok {
+		return "❌ Не понял время. Например: \"утренняя сводка в 8:00\""
+	}
+	at := formatClock(clock)
+
+	// Если время на сегодня уже прошло, первая рассылка будет завтра
+	today := now.Format(habitDateLayout)
+	passed := now.Sub(startOfDay(now)) >= clock
+	if morning {
+		data.Settings.MorningDigestAt = at
+		if passed {
+			data.DigestSentOn = today
+		}
+	} else {
+		data.Settings.EveningReviewAt = at
+		if passed {
+			data.ReviewSentOn = today
+		}
+	}
+	if err := h.storage.SaveUserData(data); err != nil {
+		return "❌ Ошибка при сохранении настроек"
+	}
+
+	response := fmt.Sprintf("✅ Буду присылать %s каждый день в %s (%s)", digestTitle(match[1]), at, describeUTCOffset(now))
+	if !data.Settings.NotificationsEnabled {
+		response += "\n\n⚠️ Уведомления выключены - напиши \"включить уведомления\", иначе сообщения не придут."
+	}
+	return response
+}
+
+// setTimezone задает часовой пояс пользователя: "часовой пояс +3", "часовой пояс мск"
+func (h *Handler) setTimezone(value, userID string) string {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+
+	if value == "" {
+		now := h.userNow(userID)
+		return fmt.Sprintf("🌍 Часовой пояс: %s, сейчас у тебя %s\n\nИзменить: \"часовой пояс +3\" или \"часовой пояс мск\"", describeUTCOffset(now), now.Format("15:04"))
+	}
+
+	offset, ok := parseUTCOffset(value)
+	if !ok {
+		return "❌ Не понял часовой пояс. Например: \"часовой пояс +3\", \"часовой пояс -5\" или \"часовой пояс мск\""
+	}
+	data.Settings.UTCOffset = &offset
+	if err := h.storage.SaveUserData(data); err != nil {
+		return "❌ Ошибка при сохранении настроек"
+	}
+
+	now := h.userNow(userID)
+	return fmt.Sprintf("🌍 Часовой пояс: %s, сейчас у тебя %s", describeUTCOffset(now), now.Format("15:04"))
+}
+
+// processDigests рассылает утренние сводки и вечерние итоги по местному времени пользователей
+func (h *Handler) processDigests(ctx context.Context, api *maxbot.Api, now time.Time) {
+	users, _ := h.storage.GetUsers()
+	for _, user := range users {
+		userID := user.MAXUserID
+		data, _ := h.storage.GetUserData(userID)
+		if data == nil {
+			continue
+		}
+
+		local := now.In(h.userLocation(userID))
+		today := local.Format(habitDateLayout)
+		changed := false
+
+		// Если отправка не удалась, попробуем снова на следующем шаге планировщика
+		if digestDue(data.Settings.MorningDigestAt, data.DigestSentOn, local) &&
+			h.notify(ctx, api, userID, notifyDigest, h.morningDigest(userID, local), nil) {
+			data.DigestSentOn = today
+			changed = true
+		}
+		if digestDue(data.Settings.EveningReviewAt, data.ReviewSentOn, local) {
+			review, keyboard := h.eveningReview(api, userID, local)
+			if h.notify(ctx, api, userID, notifyDigest, review, keyboard) {
+				data.ReviewSentOn = today
+				changed = true
+			}
+		}
+
+		if changed {
+			h.storage.SaveUserData(data)
+		}
+	}
+}
+
+// digestDue - наступило ли время рассылки и не отправляли ли ее сегодня
+func digestDue(at, sentOn string, local time.Time) bool {
+	if at == "" || sentOn == local.Format(habitDateLayout) {
+		return false
+	}
+	clock, ok := parseClock(at)
+	return ok && local.Sub(startOfDay(local)) >= clock
+}
+
+// morningDigest - задачи на сегодня, просроченные задачи и цели, которым нужно внимание
+func (h *Handler) morningDigest(userID string, now time.Time) string {
+	today, tomorrow := startOfDay(now), startOfDay(now).AddDate(0, 0, 1)
+
+	var overdue, dueToday []*models.Task
+	tasks, _ := h.storage.GetUserTasks(userID)
+	for _, task := range tasks {
+		if task.Completed || task.Deadline == nil {
+			continue
+		}
+		switch {
+		case task.Deadline.Before(today):
+			overdue = append(overdue, task)
+		case task.Deadline.Before(tomorrow):
+			dueToday = append(dueToday, task)
+		}
+	}
+	sortByDeadline(overdue)
+	sortByDeadline(dueToday)
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("☀️ Доброе утро! План на %s %s\n", weekdayShortNames[now.Weekday()], now.Format("02.01")))
+
+	if len(overdue) > 0 {
+		response.WriteString("\n🔥 Просрочено:\n")
+		for _, task := range overdue {
+			response.WriteString(fmt.Sprintf("• %s (срок %s)\n", task.Text, formatWhen(task.Deadline.In(now.Location()))))
+		}
+	}
+	if len(dueToday) > 0 {
+		response.WriteString("\n📅 На сегодня:\n")
+		for _, task := range dueToday {
+			response.WriteString("• " + task.Text)
+			if deadline := task.Deadline.In(now.Location()); deadline.Hour() != 23 || deadline.Minute() != 59 {
+				response.WriteString(" ⏰ " + deadline.Format("15:04"))
+			}
+			response.WriteString("\n")
+		}
+	}
+	if len(overdue) == 0 && len(dueToday) == 0 {
+		response.WriteString("\n📅 Задач со сроком на сегодня нет - можно заняться важным без спешки\n")
+	}
+
+	if focus := h.focusGoals(userID, now); len(focus) > 0 {
+		response.WriteString("\n🎯 Фокус на целях:\n")
+		for _, goal := range focus {
+			pace, _ := goalForecast(goal, now)
+			response.WriteString(fmt.Sprintf("• %s - %d%%, %s\n", goal.Title, goal.Progress, paceTitles[pace]))
+			for _, step := range goal.Steps {
+				if !step.Completed {
+					response.WriteString(fmt.Sprintf("  следующий шаг: %s\n", step.Text))
+					break
+				}
+			}
+		}
+	}
+
+	if _, _, habitsDue, _ := h.habitsSummary(userID, now); habitsDue > 0 {
+		response.WriteString(fmt.Sprintf("\n🌱 Привычек на сегодня: %d\n", habitsDue))
+	}
+
+	response.WriteString("\nХорошего дня! Начать фокус: \"старт помодоро\" 🍅")
+	return response.String()
+}
+
+// focusGoals - до двух активных целей, которым больше всего нужно внимание: сначала отстающие, затем с ближайшим сроком
+func (h *Handler) focusGoals(userID string, now time.Time) []*models.Goal {
+	goals, _ := h.storage.GetUserGoals(userID)
+	var active []*models.Goal
+	for _, goal := range goals {
+		if !goal.Completed {
+			active = append(active, goal)
+		}
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		left, _ := goalForecast(active[i], now)
+		right, _ := goalForecast(active[j], now)
+		if left != right {
+			return left > right
+		}
+		return active[i].Deadline.Before(active[j].Deadline)
+	})
+	if len(active) > 2 {
+		active = active[:2]
+	}
+	return active
+}
+
+// eveningReview - итоги дня и кнопки переноса невыполненных задач на завтра
+func (h *Handler) eveningReview(api *maxbot.Api, userID string, now time.Time) (string, *maxbot.Keyboard) {
+	today, tomorrow := startOfDay(now), startOfDay(now).AddDate(0, 0, 1)
+
+	pomodoros, minutes := 0, 0
+	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
+	for _, session := range sessions {
+		if session.Type == "work" && session.Completed && sameDay(session.StartTime.In(now.Location()), now) {
+			pomodoros++
+			minutes += session.Duration
+		}
+	}
+
+	var completed, unfinished []*models.Task
+	tasks, _ := h.storage.GetUserTasks(userID)
+	for _, task := range tasks {
+		switch {
+		case task.Completed && task.CompletedAt != nil && !task.CompletedAt.Before(today):
+			completed = append(completed, task)
+		case !task.Completed && task.Deadline != nil && task.Deadline.Before(tomorrow):
+			unfinished = append(unfinished, task)
+		}
+	}
+	sortByDeadline(unfinished)
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🌙 Итоги дня %s\n\n", now.Format("02.01")))
+	response.WriteString(fmt.Sprintf("🍅 Помодоро: %d (%d мин. фокуса)\n", pomodoros, minutes))
+	response.WriteString(fmt.Sprintf("✅ Выполнено задач: %d\n", len(completed)))
+	for _, task := range completed {
+		response.WriteString("• " + task.Text + "\n")
+	}
+
+	if len(unfinished) == 0 {
+		if pomodoros > 0 || len(completed) > 0 {
+			response.WriteString("\nВсе запланированное сделано - отличный день! 🎉")
+		} else {
+			response.WriteString("\nЗавтра новый день - начни его с одного помодоро 🍅")
+		}
+		return response.String(), nil
+	}
+
+	response.WriteString(fmt.Sprintf("\n⏳ Не успел (%d):\n", len(unfinished)))
+	for _, task := range unfinished {
+		response.WriteString(fmt.Sprintf("• %s (срок %s)\n", task.Text, formatWhen(task.Deadline.In(now.Location()))))
+	}
+	response.WriteString("\nПеренести на завтра?")
+
+	keyboard := api.Messages.NewKeyboardBuilder()
+	for i, task := range unfinished {
+		if i == reviewButtonsLimit {
+			break
+		}
+		keyboard.AddRow().AddCallback("➡️ "+task.Text, schemes.DEFAULT, "task_tomorrow_"+task.ID)
+	}
+	if len(unfinished) > 1 {
+		keyboard.AddRow().AddCallback("➡️ Перенести все на завтра", schemes.POSITIVE, "task_tomorrow_all")
+	}
+	return response.String(), keyboard
+}
+
+// moveTasksToTomorrow переносит срок задачи (или всех невыполненных за сегодня при taskID == "all") на завтра
+func (h *Handler) moveTasksToTomorrow(ctx context.Context, api *maxbot.Api, userID string, chatID int64, taskID string) {
+	now := h.userNow(userID)
+	tomorrow := startOfDay(now).AddDate(0, 0, 1)
+
+	var moved []string
+	tasks, _ := h.storage.GetUserTasks(userID)
+	for _, task := range tasks {
+		if task.Completed || task.Deadline == nil {
+			continue
+		}
+		if taskID == "all" && !task.Deadline.Before(tomorrow) || taskID != "all" && task.ID != taskID {
+			continue
+		}
+
+		deadline := task.Deadline.In(now.Location())
+		next := tomorrow.Add(deadline.Sub(startOfDay(deadline)))
+		task.History = append(task.History, models.TaskEdit{
+			Field:    "deadline",
+			OldValue: formatWhen(deadline),
+			NewValue: formatWhen(next),
+			Changed:  time.Now(),
+		})
+		task.Deadline = &next
+		task.ReminderSent = false
+		task.OverdueNotified = false
+		if err := h.storage.UpdateTask(task); err != nil {
+			continue
+		}
+		moved = append(moved, task.Text)
+	}
+
+	response := "🤷 Переносить нечего - задача уже выполнена или удалена"
+	if len(moved) == 1 {
+		response = fmt.Sprintf("➡️ Задача \"%s\" перенесена на завтра", moved[0])
+	} else if len(moved) > 1 {
+		response = fmt.Sprintf("➡️ Перенесено на завтра задач: %d", len(moved))
+	}
+	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+}
+
+// userLocation - часовой пояс пользователя (по умолчанию - время сервера)
+func (h *Handler) userLocation(userID string) *time.Location {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil || data.Settings.UTCOffset == nil {
+		return time.Local
+	}
+	offset := *data.Settings.UTCOffset
+	return time.FixedZone(formatUTCOffset(offset), offset*60)
+}
+
+// userNow - текущее время в часовом поясе пользователя
+func (h *Handler) userNow(userID string) time.Time {
+	return time.Now().In(h.userLocation(userID))
+}
+
+// parseUTCOffset разбирает смещение "+3", "-5", "+5:30", "мск" и возвращает его в минутах
+func parseUTCOffset(value string) (int, bool) {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "utc"), "gmt")
+	value = strings.TrimSpace(value)
+	if value == "мск" || strings.HasPrefix(value, "москв") {
+		return 3 * 60, true
+	}
+
+	match := utcOffsetPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false
+	}
+	hours, _ := strconv.Atoi(match[2])
+	minutes := 0
+	if match[3] != "" {
+		minutes, _ = strconv.Atoi(match[3])
+	}
+	offset := hours*60 + minutes
+	if match[1] == "-" {
+		offset = -offset
+	}
+	if offset < -12*60 || offset > 14*60 || minutes >= 60 {
+		return 0, false
+	}
+	return offset, true
+}
+
+func formatUTCOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	if offset%60 != 0 {
+		return fmt.Sprintf("UTC%s%d:%02d", sign, offset/60, offset%60)
+	}
+	return fmt.Sprintf("UTC%s%d", sign, offset/60)
+}
+
+func describeUTCOffset(now time.Time) string {
+	_, seconds := now.Zone()
+	return formatUTCOffset(seconds / 60)
+}
+
+func digestTitle(command string) string {
+	if strings.HasPrefix(command, "утренн") {
+		return "утреннюю сводку"
+	}
+	return "вечерний итог"
+}
+
+func digestSchedule(at, example string) string {
+	if at == "" {
+		return fmt.Sprintf("⏰ Присылать каждый день: \"%s\"", example)
+	}
+	return fmt.Sprintf("⏰ Присылаю каждый день в %s", at)
+}
+
+func sortByDeadline(tasks []*models.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Deadline.Before(*tasks[j].Deadline)
+	})
+}
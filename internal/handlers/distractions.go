@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== DISTRACTION LOG ==========
+
+// distractionButtonsLimit - для скольких отвлечений предлагать кнопки "в задачи"
+const distractionButtonsLimit = 5
+
+var distractionPattern = regexp.MustCompile(`^отвл[её]к\S*\s*:?\s*(.*)$`)
+
+// logDistraction записывает отвлечение в текущую сессию: "отвлёкся: проверить почту"
+func (h *Handler) logDistraction(text, userID string) string {
+	thought := strings.TrimSpace(distractionPattern.FindStringSubmatch(text)[1])
+	if thought == "" {
+		return "❌ Напиши, что отвлекло. Например: \"отвлёкся: проверить почту\""
+	}
+
+	session := h.activeWorkSession(userID)
+	if session == nil {
+		return fmt.Sprintf("🤷 Сейчас нет активной помодоро-сессии. Если это дело - добавь задачу: \"добавить задачу %s\"", thought)
+	}
+
+	session.Distractions = append(session.Distractions, models.Distraction{Text: thought, Time: time.Now()})
+	if err := h.storage.UpdatePomodoroSession(session); err != nil {
+		return "❌ Ошибка при сохранении"
+	}
+
+	return fmt.Sprintf("📝 Записал: \"%s\". После сессии предложу сделать из этого задачу - а пока возвращайся к фокусу! 🎯", thought)
+}
+
+// activeWorkSession - идущая сейчас рабочая сессия пользователя
+func (h *Handler) activeWorkSession(userID string) *models.PomodoroSession {
+	if _, running := h.activeTimers[userID]; !running {
+		return nil
+	}
+	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
+	if len(sessions) == 0 {
+		return nil
+	}
+	session := sessions[len(sessions)-1]
+	if session.Type != "work" || session.Completed || session.Interrupted {
+		return nil
+	}
+	return session
+}
+
+// distractionsOffer - список отвлечений сессии с кнопками превращения их в задачи.
+// Возвращает пустую строку, если отвлечений не было.
+func (h *Handler) distractionsOffer(api *maxbot.Api, session *models.PomodoroSession) (string, *maxbot.Keyboard) {
+	var pending []int
+	for i, distraction := range session.Distractions {
+		if distraction.TaskID == "" {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return "", nil
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("💭 За сессию отвлекало (%d):\n", len(pending)))
+	for _, i := range pending {
+		response.WriteString(fmt.Sprintf("• %s\n", session.Distractions[i].Text))
+	}
+	response.WriteString("\nДобавить в задачи?")
+
+	keyboard := api.Messages.NewKeyboardBuilder()
+	for n, i := range pending {
+		if n == distractionButtonsLimit {
+			break
+		}
+		keyboard.AddRow().AddCallback("➕ "+session.Distractions[i].Text, schemes.DEFAULT, fmt.Sprintf("pomodoro_distraction_%s_%d", session.ID, i))
+	}
+	if len(pending) > 1 {
+		keyboard.AddRow().AddCallback("➕ Добавить все", schemes.POSITIVE, fmt.Sprintf("pomodoro_distraction_%s_all", session.ID))
+	}
+	return response.String(), keyboard
+}
+
+// distractionToTask создает задачи из отвлечений: pomodoro_distraction_<sessionID>_<index|all>
+func (h *Handler) distractionToTask(ctx context.Context, api *maxbot.Api, userID string, chatID int64, payload string) {
+	separator := strings.LastIndex(payload, "_")
+	if separator < 0 {
+		return
+	}
+	sessionID, which := payload[:separator], payload[separator+1:]
+
+	var session *models.PomodoroSession
+	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
+	for _, candidate := range sessions {
+		if candidate.ID == sessionID {
+			session = candidate
+		}
+	}
+	if session == nil {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("❌ Сессия не найдена"))
+		return
+	}
+
+	var added []string
+	var converted []int
+	for i := range session.Distractions {
+		distraction := &session.Distractions[i]
+		if distraction.TaskID != "" || which != "all" && which != strconv.Itoa(i) {
+			continue
+		}
+		task := newTask(userID, distraction.Text, h.userNow(userID))
+		if err := h.storage.SaveTask(task); err != nil {
+			continue
+		}
+		distraction.TaskID = task.ID
+		added = append(added, task.Text)
+		converted = append(converted, i)
+	}
+	if err := h.storage.UpdatePomodoroSession(session); err != nil {
+		// Без сохраненной связи кнопки предлагали бы те же отвлечения снова - убираем созданные задачи
+		for _, i := range converted {
+			h.storage.DeleteTask(userID, session.Distractions[i].TaskID)
+			session.Distractions[i].TaskID = ""
+		}
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("❌ Ошибка при сохранении"))
+		return
+	}
+
+	response := "🤷 Эти отвлечения уже добавлены в задачи"
+	if len(added) == 1 {
+		response = fmt.Sprintf("✅ Добавлена задача: \"%s\"", added[0])
+	} else if len(added) > 1 {
+		response = fmt.Sprintf("✅ Добавлено задач: %d\n• %s", len(added), strings.Join(added, "\n• "))
+	}
+	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+}
+
+// distractionStats - сколько всего было отвлечений и в скольких рабочих сессиях
+func (h *Handler) distractionStats(userID string) (int, int) {
+	total, sessionsCount := 0, 0
+	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
+	for _, session := range sessions {
+		if session.Type != "work" {
+			continue
+		}
+		sessionsCount++
+		total += len(session.Distractions)
+	}
+	return total, sessionsCount
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"proddy-bot/internal/analytics"
+	"proddy-bot/internal/models"
+)
+
+// ========== POMODORO ESTIMATES ==========
+
+const (
+	// estimateMax - больше сессий на одну задачу - повод разбить ее на части
+	estimateMax = 50
+	// estimateReportWeeks - за сколько недель показывать динамику точности оценок
+	estimateReportWeeks = 8
+	// estimateReportTasks - сколько последних задач показывать в отчете
+	estimateReportTasks = 5
+)
+
+// estimatePattern - оценка в описании задачи: "отчёт ~3🍅", "отчёт ~3 помидора" или "отчёт ~3" в конце.
+// Без единицы в середине текста "~" - это приблизительное количество: "купить ~2 кг яблок".
+var estimatePattern = regexp.MustCompile(`\s*~\s*(\d+)\s*(?:🍅|помидор\S*|$)`)
+
+// parseEstimate выделяет из описания задачи оценку в помодоро.
+// Возвращает 0 и исходный текст, если оценки нет.
+func parseEstimate(description string) (int, string) {
+	match := estimatePattern.FindStringSubmatchIndex(description)
+	if match == nil {
+		return 0, description
+	}
+	estimate, err := strconv.Atoi(description[match[2]:match[3]])
+	if err != nil || estimate <= 0 || estimate > estimateMax {
+		return 0, description
+	}
+	rest := strings.TrimSpace(description[:match[0]] + " " + description[match[1]:])
+	if rest == "" {
+		return 0, description
+	}
+	return estimate, strings.Join(strings.Fields(rest), " ")
+}
+
+// formatEstimate - оценка для вывода: "~3🍅"
+func formatEstimate(estimate int) string {
+	if estimate <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("~%d🍅", estimate)
+}
+
+// parseEstimateValue разбирает новую оценку в "изменить оценку задачи 1 4": 0 - убрать оценку
+func parseEstimateValue(value string) (int, bool) {
+	value = strings.TrimSpace(strings.Trim(value, "~🍅 "))
+	if value == "" || value == "нет" || value == "без оценки" {
+		return 0, true
+	}
+	estimate, err := strconv.Atoi(value)
+	if err != nil || estimate < 0 || estimate > estimateMax {
+		return 0, false
+	}
+	return estimate, true
+}
+
+// taskPomodoros - сколько помодоро завершено по каждой задаче пользователя
+func (h *Handler) taskPomodoros(userID string) map[string]int {
+	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
+	return analytics.TaskPomodoros(sessions)
+}
+
+// pomodoroTask - задача из команды "старт помодоро 2": номер как в "список задач"
+func (h *Handler) pomodoroTask(text, userID string) (*models.Task, string) {
+	tasks, _ := h.storage.GetUserTasks(userID)
+	number, _ := extractNumber(text, len(tasks))
+	if number == 0 {
+		if strings.Contains(text, "задач") {
+			return nil, "❌ Укажи номер задачи из списка. Например: \"старт помодоро 2\""
+		}
+		return nil, ""
+	}
+	task := tasks[number-1]
+	if task.Completed {
+		return nil, fmt.Sprintf("✅ Задача уже выполнена: \"%s\"", task.Text)
+	}
+	return task, ""
+}
+
+// taskPomodoroProgress - сколько сессий потрачено на задачу по сравнению с оценкой
+func taskPomodoroProgress(task *models.Task, done int) string {
+	if task.Estimate <= 0 {
+		return fmt.Sprintf("📌 \"%s\": 🍅 %d", task.Text, done)
+	}
+	progress := fmt.Sprintf("📌 \"%s\": 🍅 %d из %s", task.Text, done, formatEstimate(task.Estimate))
+	if done > task.Estimate {
+		progress += " - уже больше оценки. Может, стоит разбить задачу на части?"
+	}
+	return progress
+}
+
+// estimateResult - итог по оценке для сообщения о выполнении задачи
+func (h *Handler) estimateResult(task *models.Task) string {
+	if task.Estimate <= 0 {
+		return ""
+	}
+	actual := h.taskPomodoros(task.UserID)[task.ID]
+	switch {
+	case actual == task.Estimate:
+		return fmt.Sprintf("🎯 Точно в оценку: %d🍅", actual)
+	case actual > task.Estimate:
+		return fmt.Sprintf("🍅 Потрачено %d при оценке %s", actual, formatEstimate(task.Estimate))
+	default:
+		return fmt.Sprintf("🍅 Потрачено %d при оценке %s - быстрее, чем планировал!", actual, formatEstimate(task.Estimate))
+	}
+}
+
+func isEstimateReportCommand(text string) bool {
+	return text == "оценки" || strings.Contains(text, "точность оцен")
+}
+
+// estimateReport - насколько оценки задач в помодоро совпадают с фактом и как это меняется
+func (h *Handler) estimateReport(userID string) string {
+	estimates := analytics.AnalyzeEstimates(h.analyticsInput(userID), h.userNow(userID), estimateReportWeeks)
+	if estimates.Total.Tasks == 0 {
+		return "🍅 Пока нет выполненных задач с оценкой.\n\nДобавляй задачи с оценкой в помодоро: \"добавить задачу отчёт ~3🍅\", а работая над задачей, запускай \"старт помодоро 1\" (номер из списка задач) - и я покажу, насколько точно ты планируешь."
+	}
+
+	var response strings.Builder
+	response.WriteString("🎯 Точность оценок\n\n")
+	response.WriteString(fmt.Sprintf("📝 Задач с оценкой: %d\n", estimates.Total.Tasks))
+	writeEstimateStats(&response, estimates.Total)
+
+	var ratios []int
+	var trend strings.Builder
+	for _, week := range estimates.Weeks {
+		if week.Tasks == 0 {
+			continue
+		}
+		trend.WriteString(fmt.Sprintf("• %s-%s: %d%% от оценки, точно %d из %d\n", week.Period.Start.Format("02.01"), week.Period.End.AddDate(0, 0, -1).Format("02.01"), week.Ratio(), week.Exact, week.Tasks))
+		ratios = append(ratios, week.Ratio())
+	}
+	if len(ratios) > 1 {
+		response.WriteString("\n📅 По неделям:\n")
+		response.WriteString(trend.String())
+		first, last := ratios[0], ratios[len(ratios)-1]
+		if abs(last-100) < abs(first-100) {
+			response.WriteString("📈 Оценки становятся точнее - так держать!\n")
+		} else if abs(last-100) > abs(first-100) {
+			response.WriteString("📉 Оценки стали расходиться с фактом сильнее\n")
+		}
+	}
+
+	response.WriteString("\n🕑 Последние задачи:\n")
+	for i, estimate := range estimates.Tasks {
+		if i == estimateReportTasks {
+			break
+		}
+		response.WriteString(fmt.Sprintf("• %s: %s → %d🍅\n", estimate.Task.Text, formatEstimate(estimate.Task.Estimate), estimate.Actual))
+	}
+
+	return strings.TrimRight(response.String(), "\n")
+}
+
+// writeEstimateStats выводит соотношение факта и оценок с подсказкой
+func writeEstimateStats(response *strings.Builder, stats analytics.EstimateStats) {
+	response.WriteString(fmt.Sprintf("🍅 Запланировано %d, потрачено %d (%d%% от оценки)\n", stats.Estimated, stats.Actual, stats.Ratio()))
+	response.WriteString(fmt.Sprintf("• точно: %d, дольше: %d, быстрее: %d\n", stats.Exact, stats.Under, stats.Over))
+
+	switch ratio := stats.Ratio(); {
+	case ratio > 120:
+		response.WriteString("💡 Задачи занимают больше, чем кажется - закладывай запас\n")
+	case ratio < 80:
+		response.WriteString("💡 Ты справляешься быстрее, чем планируешь - можно брать больше\n")
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import "testing"
+
+func TestParseEstimate(t *testing.T) {
+	tests := []struct {
+		description string
+		estimate    int
+		rest        string
+	}{
+		{"отчёт ~3🍅", 3, "отчёт"},
+		{"отчёт ~ 3 🍅", 3, "отчёт"},
+		{"отчёт ~3 помидора", 3, "отчёт"},
+		{"отчёт ~3", 3, "отчёт"},
+		{"~2🍅 подготовить слайды к пятнице", 2, "подготовить слайды к пятнице"},
+		{"купить ~2 кг яблок", 0, "купить ~2 кг яблок"},
+		{"отчёт ~0🍅", 0, "отчёт ~0🍅"},
+		{"отчёт ~51🍅", 0, "отчёт ~51🍅"},
+		{"~3🍅", 0, "~3🍅"},
+		{"отчёт", 0, "отчёт"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			estimate, rest := parseEstimate(tt.description)
+			if estimate != tt.estimate || rest != tt.rest {
+				t.Errorf("parseEstimate() = %d, %q, want %d, %q", estimate, rest, tt.estimate, tt.rest)
+			}
+		})
+	}
+}
+
+func TestParseEstimateValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		estimate int
+		ok       bool
+	}{
+		{"4", 4, true},
+		{"~4🍅", 4, true},
+		{"нет", 0, true},
+		{"без оценки", 0, true},
+		{"", 0, true},
+		{"много", 0, false},
+		{"51", 0, false},
+	}
+
+	for _, tt := range tests {
+		if estimate, ok := parseEstimateValue(tt.value); estimate != tt.estimate || ok != tt.ok {
+			t.Errorf("parseEstimateValue(%q) = %d, %v, want %d, %v", tt.value, estimate, ok, tt.estimate, tt.ok)
+		}
+	}
+}
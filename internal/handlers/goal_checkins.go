@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== PERIODIC GOAL CHECK-INS ==========
+
+// goalCheckInHour - час, в который бот спрашивает о прогрессе в день проверки
+const goalCheckInHour = 18
+
+var (
+	checkInSchedulePattern = regexp.MustCompile(`^(?:еженедельн\S*\s+)?провер\S*\s+цел\S*\s+(\d+)\s*(.*)$`)
+	checkInDisablePattern  = regexp.MustCompile(`^(?:отключ|выключ)\S*\s+провер\S*\s+цел\S*\s+(\d+)$`)
+)
+
+var weekdayTitles = map[time.Weekday]string{
+	time.Monday:    "понедельникам",
+	time.Tuesday:   "вторникам",
+	time.Wednesday: "средам",
+	time.Thursday:  "четвергам",
+	time.Friday:    "пятницам",
+	time.Saturday:  "субботам",
+	time.Sunday:    "воскресеньям",
+}
+
+func isGoalCheckInCommand(text string) bool {
+	return checkInSchedulePattern.MatchString(text) || checkInDisablePattern.MatchString(text)
+}
+
+// setGoalCheckInDay настраивает день еженедельной проверки цели: "проверка цели 1 по пятницам"
+func (h *Handler) setGoalCheckInDay(text, userID string) string {
+	if match := checkInDisablePattern.FindStringSubmatch(text); match != nil {
+		goal, ok := h.goalByNumber(userID, match[1])
+		if !ok {
+			return "❌ Цель с таким номером не найдена"
+		}
+		goal.CheckInDay = nil
+		if err := h.storage.UpdateGoal(goal); err != nil {
+			return "❌ Ошибка при обновлении цели"
+		}
+		return fmt.Sprintf("🔕 Еженедельная проверка цели \"%s\" выключена", goal.Title)
+	}
+
+	match := checkInSchedulePattern.FindStringSubmatch(text)
+	goal, ok := h.goalByNumber(userID, match[1])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+
+	weekday, found := time.Weekday(0), false
+	for _, field := range strings.Fields(match[2]) {
+		if weekday, found = parseWeekday(field); found {
+			break
+		}
+	}
+	if !found {
+		if goal.CheckInDay == nil {
+			return fmt.Sprintf("🔕 Проверка цели \"%s\" выключена. Включить: \"проверка цели %s по пятницам\"", goal.Title, match[1])
+		}
+		return fmt.Sprintf("🗓 Цель \"%s\" проверяем по %s. Изменить: \"проверка цели %s по пятницам\"", goal.Title, weekdayTitles[*goal.CheckInDay], match[1])
+	}
+
+	goal.CheckInDay = &weekday
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		return "❌ Ошибка при обновлении цели"
+	}
+	return fmt.Sprintf("🗓 Буду спрашивать о прогрессе цели \"%s\" по %s в %d:00", goal.Title, weekdayTitles[weekday], goalCheckInHour)
+}
+
+// setGoalStaleDays настраивает напоминание о забытых целях: "напоминать о целях через 5 дней"
+func (h *Handler) setGoalStaleDays(text, userID string) string {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+
+	days := -1
+	if strings.Contains(text, "не ") || strings.Contains(text, "никогда") {
+		days = 0
+	} else {
+		for _, field := range strings.Fields(text) {
+			if n, err := strconv.Atoi(field); err == nil && n > 0 {
+				days = n
+				break
+			}
+		}
+	}
+	if days < 0 {
+		return "❌ Например: \"напоминать о целях через 5 дней\" или \"не напоминать о целях\""
+	}
+
+	data.Settings.GoalStaleDays = days
+	if err := h.storage.SaveUserData(data); err != nil {
+		return "❌ Ошибка при сохранении настроек"
+	}
+	if days == 0 {
+		return "🔕 Не буду напоминать о целях без обновлений"
+	}
+	return fmt.Sprintf("✅ Напомню о цели, если по ней не будет новостей %d дн.", days)
+}
+
+// processGoalCheckIns задает вопросы еженедельных проверок и напоминает о забытых целях
+func (h *Handler) processGoalCheckIns(ctx context.Context, api *maxbot.Api, now time.Time) {
+	users, _ := h.storage.GetUsers()
+	for _, user := range users {
+		userID := user.MAXUserID
+
+		staleDays := 7
+		if data, _ := h.storage.GetUserData(userID); data != nil {
+			staleDays = data.Settings.GoalStaleDays
+		}
+
+		goals, _ := h.storage.GetUserGoals(userID)
+		for _, goal := range goals {
+			if goal.Completed {
+				continue
+			}
+
+			switch {
+			case goalCheckInDue(goal, now.In(h.userLocation(userID))):
+				text := fmt.Sprintf("🗓 Еженедельная проверка цели \"%s\"\n%s %d%%\n\nКак продвигается?", goal.Title, h.createProgressBar(goal.Progress), goal.Progress)
+				if !h.notify(ctx, api, userID, notifyCheckIn, text, h.goalCheckInKeyboard(api, goal)) {
+					continue
+				}
+				goal.CheckInAskedAt = &now
+			case staleDays > 0 && goalStale(goal, now, staleDays):
+				text := fmt.Sprintf("👀 По цели \"%s\" не было новостей уже %d дн. Как успехи?", goal.Title, int(now.Sub(lastGoalActivity(goal)).Hours()/24))
+				if !h.notify(ctx, api, userID, notifyCheckIn, text, h.goalCheckInKeyboard(api, goal)) {
+					continue
+				}
+				goal.NudgedAt = &now
+			default:
+				continue
+			}
+			h.storage.UpdateGoal(goal)
+		}
+	}
+}
+
+func goalCheckInDue(goal *models.Goal, now time.Time) bool {
+	if goal.CheckInDay == nil || now.Weekday() != *goal.CheckInDay || now.Hour() < goalCheckInHour {
+		return false
+	}
+	if goal.CheckInAskedAt == nil {
+		return true
+	}
+	asked := goal.CheckInAskedAt.In(now.Location())
+	return asked.YearDay() != now.YearDay() || asked.Year() != now.Year()
+}
+
+// goalStale - цель не обновлялась staleDays дней и о ней еще не напоминали после последнего обновления
+func goalStale(goal *models.Goal, now time.Time, staleDays int) bool {
+	last := lastGoalActivity(goal)
+	if now.Sub(last) < time.Duration(staleDays)*24*time.Hour {
+		return false
+	}
+	if goal.NudgedAt != nil && goal.NudgedAt.After(last) {
+		// Повторяем напоминание не чаще, чем раз в staleDays дней
+		return now.Sub(*goal.NudgedAt) >= time.Duration(staleDays)*24*time.Hour
+	}
+	return true
+}
+
+// lastGoalActivity - время последней отметки по цели (или ее создания)
+func lastGoalActivity(goal *models.Goal) time.Time {
+	if len(goal.CheckIns) > 0 {
+		return goal.CheckIns[len(goal.CheckIns)-1].Date
+	}
+	return goal.Created
+}
+
+// goalCheckInKeyboard - быстрые ответы на вопрос о прогрессе: goal_checkin_<goalID>_<answer>
+func (h *Handler) goalCheckInKeyboard(api *maxbot.Api, goal *models.Goal) *maxbot.Keyboard {
+	payload := func(answer string) string {
+		return fmt.Sprintf("goal_checkin_%s_%s", goal.ID, answer)
+	}
+
+	keyboard := api.Messages.NewKeyboardBuilder()
+	row := keyboard.AddRow()
+	switch {
+	case goal.Target > 0:
+		row.AddCallback(fmt.Sprintf("+1 %s", goal.Unit), schemes.POSITIVE, payload("v1"))
+		row.AddCallback(fmt.Sprintf("+5 %s", goal.Unit), schemes.POSITIVE, payload("v5"))
+	case len(goal.Steps) == 0 && !goal.ProgressFromTasks:
+		row.AddCallback("+10%", schemes.POSITIVE, payload("p10"))
+		row.AddCallback("+25%", schemes.POSITIVE, payload("p25"))
+		row.AddCallback("🏁 Достигнута", schemes.POSITIVE, payload("done"))
+	default:
+		row.AddCallback("👍 Идет по плану", schemes.POSITIVE, payload("ok"))
+	}
+	keyboard.AddRow().AddCallback("😐 Без изменений", schemes.DEFAULT, payload("same"))
+	return keyboard
+}
+
+// handleGoalCheckInCallback записывает ответ на вопрос о прогрессе как отметку по цели
+func (h *Handler) handleGoalCheckInCallback(ctx context.Context, api *maxbot.Api, userID string, chatID int64, payload string) {
+	separator := strings.LastIndex(payload, "_")
+	if separator < 0 {
+		return
+	}
+	goalID, answer := payload[:separator], payload[separator+1:]
+
+	var goal *models.Goal
+	goals, _ := h.storage.GetUserGoals(userID)
+	for _, candidate := range goals {
+		if candidate.ID == goalID {
+			goal = candidate
+		}
+	}
+	if goal == nil {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("❌ Цель не найдена"))
+		return
+	}
+	if goal.Completed {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(fmt.Sprintf("✅ Цель \"%s\" уже достигнута", goal.Title)))
+		return
+	}
+
+	wasCompleted := goal.Completed
+	switch answer {
+	case "v1", "v5":
+		amount, _ := strconv.Atoi(answer[1:])
+		h.recordGoalValue(goal, goal.Current+float64(amount), "проверка")
+	case "p10", "p25":
+		amount, _ := strconv.Atoi(answer[1:])
+		h.setGoalProgress(goal, min(goal.Progress+amount, 100))
+		recordGoalCheckIn(goal, "проверка")
+	case "done":
+		h.setGoalProgress(goal, 100)
+		recordGoalCheckIn(goal, "проверка")
+	case "ok":
+		recordGoalCheckIn(goal, "идет по плану")
+	default:
+		recordGoalCheckIn(goal, "без изменений")
+	}
+
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("❌ Ошибка при обновлении цели"))
+		return
+	}
+
+	response := fmt.Sprintf("📝 Записал! \"%s\": %d%%\n%s", goal.Title, goal.Progress, describeGoalForecast(goal, time.Now()))
+	switch {
+	case goal.Completed && !wasCompleted:
+		response = h.goalCompletedMessage(goal)
+	case answer == "same":
+		response = fmt.Sprintf("📝 Записал. Может, выделить время на \"%s\" на этой неделе? Поможет \"старт помодоро\" 🍅", goal.Title)
+	}
+	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+}
+
+// parseWeekday понимает день недели в любой форме: "пт", "пятница", "по пятницам"
+func parseWeekday(field string) (time.Weekday, bool) {
+	if weekday, exists := weekdayNames[field]; exists {
+		return weekday, true
+	}
+	for name, weekday := range weekdayNames {
+		runes := []rune(name)
+		if len(runes) > 3 && strings.HasPrefix(field, string(runes[:len(runes)-1])) {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
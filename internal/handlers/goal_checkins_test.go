@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWeekday(t *testing.T) {
+	tests := []struct {
+		field string
+		want  time.Weekday
+		ok    bool
+	}{
+		{"пт", time.Friday, true},
+		{"пятница", time.Friday, true},
+		{"пятницу", time.Friday, true},
+		{"пятницам", time.Friday, true},
+		{"понедельникам", time.Monday, true},
+		{"вторникам", time.Tuesday, true},
+		{"средам", time.Wednesday, true},
+		{"четвергам", time.Thursday, true},
+		{"субботам", time.Saturday, true},
+		{"воскресеньям", time.Sunday, true},
+		{"завтра", 0, false},
+		{"п", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got, ok := parseWeekday(tt.field); got != tt.want || ok != tt.ok {
+				t.Errorf("parseWeekday(%q) = %v, %v, want %v, %v", tt.field, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"proddy-bot/internal/models"
+)
+
+// ========== GOAL FORECAST ==========
+
+var goalDetailPattern = regexp.MustCompile(`^(?:измени\S*\s+)?(описани\S*|срок\S*)\s+цел\S*\s+(\d+)\s*(.*)$`)
+
+type goalPace int
+
+const (
+	paceOnTrack goalPace = iota
+	paceAtRisk
+	paceBehind
+)
+
+// Допустимое отставание прогресса от ожидаемого (в процентных пунктах)
+const (
+	onTrackTolerance = 5
+	atRiskTolerance  = 20
+)
+
+var paceTitles = map[goalPace]string{
+	paceOnTrack: "🟢 в графике",
+	paceAtRisk:  "🟡 есть риск не успеть",
+	paceBehind:  "🔴 отстает",
+}
+
+// goalForecast сравнивает прогресс цели с долей прошедшего времени.
+// Второе значение - прогнозируемая дата достижения при текущей скорости (нулевая, если прогресса еще нет).
+func goalForecast(goal *models.Goal, now time.Time) (goalPace, time.Time) {
+	total := goal.Deadline.Sub(goal.Created)
+	elapsed := now.Sub(goal.Created)
+
+	var eta time.Time
+	if goal.Progress > 0 && elapsed > 0 {
+		eta = goal.Created.Add(time.Duration(float64(elapsed) * 100 / float64(goal.Progress)))
+	}
+
+	if !now.Before(goal.Deadline) || total <= 0 {
+		return paceBehind, eta
+	}
+
+	expected := int(float64(elapsed) / float64(total) * 100)
+	switch {
+	case goal.Progress >= expected-onTrackTolerance:
+		return paceOnTrack, eta
+	case goal.Progress >= expected-atRiskTolerance:
+		return paceAtRisk, eta
+	default:
+		return paceBehind, eta
+	}
+}
+
+// describeGoalForecast выводит срок цели и прогноз одной строкой
+func describeGoalForecast(goal *models.Goal, now time.Time) string {
+	if goal.Completed {
+		return fmt.Sprintf("📅 Срок: %s", formatWhen(goal.Deadline))
+	}
+
+	pace, eta := goalForecast(goal, now)
+	line := fmt.Sprintf("📅 Срок: %s · %s", formatWhen(goal.Deadline), paceTitles[pace])
+	switch {
+	case !now.Before(goal.Deadline):
+		line += " (срок прошел)"
+	case !eta.IsZero():
+		line += fmt.Sprintf(" (прогноз: %s)", eta.Format("02.01.2006"))
+	}
+	return line
+}
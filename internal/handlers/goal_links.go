@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"proddy-bot/internal/models"
+)
+
+// ========== TASKS LINKED TO GOALS ==========
+
+var (
+	linkTaskPattern   = regexp.MustCompile(`^привяж\S*\s+задач\S*\s+(\d+)\s+к\s+(?:шаг\S*\s+(\d+)\s+)?цел\S*\s+(\d+)$`)
+	unlinkTaskPattern = regexp.MustCompile(`^отвяж\S*\s+задач\S*\s+(\d+)`)
+	goalTasksRe       = regexp.MustCompile(`^задач\S*\s+(?:для\s+|к\s+|у\s+)?цел\S*\s+(\d+)$`)
+	progressSourceRe  = regexp.MustCompile(`^прогресс\S*\s+цел\S*\s+(\d+)\s+по\s+(задачам|шагам)$`)
+)
+
+func isGoalLinkCommand(text string) bool {
+	return linkTaskPattern.MatchString(text) || unlinkTaskPattern.MatchString(text) ||
+		goalTasksRe.MatchString(text) || progressSourceRe.MatchString(text)
+}
+
+func (h *Handler) handleGoalLinkCommand(text, userID string) string {
+	switch {
+	case linkTaskPattern.MatchString(text):
+		return h.linkTask(text, userID)
+	case unlinkTaskPattern.MatchString(text):
+		return h.unlinkTask(text, userID)
+	case progressSourceRe.MatchString(text):
+		return h.setGoalProgressSource(text, userID)
+	default:
+		return h.listGoalTasks(text, userID)
+	}
+}
+
+// linkTask привязывает задачу к цели или к шагу цели: "привязать задачу 1 к шагу 2 цели 3"
+func (h *Handler) linkTask(text, userID string) string {
+	match := linkTaskPattern.FindStringSubmatch(text)
+
+	task, ok := h.taskByNumber(userID, match[1])
+	if !ok {
+		return "❌ Задача с таким номером не найдена"
+	}
+	goal, ok := h.goalByNumber(userID, match[3])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+
+	previousGoalID := task.GoalID
+	task.GoalID = goal.ID
+	task.GoalStepID = ""
+	target := fmt.Sprintf("цели \"%s\"", goal.Title)
+	if match[2] != "" {
+		stepNumber, _ := strconv.Atoi(match[2])
+		if stepNumber < 1 || stepNumber > len(goal.Steps) {
+			return "❌ Шаг с таким номером не найден"
+		}
+		step := goal.Steps[stepNumber-1]
+		task.GoalStepID = step.ID
+		target = fmt.Sprintf("шагу \"%s\" цели \"%s\"", step.Text, goal.Title)
+	}
+
+	if err := h.storage.UpdateTask(task); err != nil {
+		return "❌ Ошибка при обновлении задачи"
+	}
+	if previousGoalID != "" && previousGoalID != goal.ID {
+		h.syncGoalWithTasks(userID, previousGoalID)
+	}
+
+	response := fmt.Sprintf("🔗 Задача \"%s\" привязана к %s", task.Text, target)
+	if achieved := h.syncGoalWithTasks(userID, goal.ID); achieved != nil {
+		response += "\n\n" + h.goalCompletedMessage(achieved)
+	}
+	if !goal.ProgressFromTasks && len(goal.Steps) == 0 {
+		response += fmt.Sprintf("\n\nСчитать прогресс цели по задачам: \"прогресс цели %s по задачам\"", match[3])
+	}
+	return response
+}
+
+func (h *Handler) unlinkTask(text, userID string) string {
+	match := unlinkTaskPattern.FindStringSubmatch(text)
+	task, ok := h.taskByNumber(userID, match[1])
+	if !ok {
+		return "❌ Задача с таким номером не найдена"
+	}
+	if task.GoalID == "" {
+		return fmt.Sprintf("🤷 Задача \"%s\" не привязана к цели", task.Text)
+	}
+
+	goalID := task.GoalID
+	task.GoalID = ""
+	task.GoalStepID = ""
+	if err := h.storage.UpdateTask(task); err != nil {
+		return "❌ Ошибка при обновлении задачи"
+	}
+	h.syncGoalWithTasks(userID, goalID)
+
+	return fmt.Sprintf("✂️ Задача \"%s\" отвязана от цели", task.Text)
+}
+
+// setGoalProgressSource переключает расчет прогресса цели: по привязанным задачам или по шагам
+func (h *Handler) setGoalProgressSource(text, userID string) string {
+	match := progressSourceRe.FindStringSubmatch(text)
+	goal, ok := h.goalByNumber(userID, match[1])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+
+	goal.ProgressFromTasks = match[2] == "задачам"
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		return "❌ Ошибка при обновлении цели"
+	}
+
+	response := fmt.Sprintf("🪜 Прогресс цели \"%s\" теперь считается по шагам", goal.Title)
+	if goal.ProgressFromTasks {
+		response = fmt.Sprintf("🔗 Прогресс цели \"%s\" теперь считается по привязанным задачам", goal.Title)
+	}
+	if achieved := h.syncGoalWithTasks(userID, goal.ID); achieved != nil {
+		response += "\n\n" + h.goalCompletedMessage(achieved)
+	}
+	return response + fmt.Sprintf("\n📈 Прогресс: %d%%", goal.Progress)
+}
+
+func (h *Handler) listGoalTasks(text, userID string) string {
+	match := goalTasksRe.FindStringSubmatch(text)
+	goal, ok := h.goalByNumber(userID, match[1])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+
+	linked := h.linkedTasks(userID, goal.ID)
+	if len(linked) == 0 {
+		return fmt.Sprintf("🔗 К цели \"%s\" пока не привязано задач.\n\nПривязать: \"привязать задачу 1 к цели %s\"", goal.Title, match[1])
+	}
+
+	stepTitles := map[string]string{}
+	for _, step := range goal.Steps {
+		stepTitles[step.ID] = step.Text
+	}
+
+	done, total := tasksProgress(linked)
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🔗 Задачи цели \"%s\" (%d/%d):\n\n", goal.Title, done, total))
+	for _, task := range linked {
+		mark := "⬜"
+		if task.Completed {
+			mark = "✅"
+		}
+		response.WriteString(fmt.Sprintf("%s %s", mark, task.Text))
+		if stepTitle, exists := stepTitles[task.GoalStepID]; exists {
+			response.WriteString(fmt.Sprintf(" (шаг: %s)", stepTitle))
+		}
+		response.WriteString("\n")
+	}
+	return response.String()
+}
+
+// linkedTasks возвращает задачи цели, включая выполненные задачи из архива
+func (h *Handler) linkedTasks(userID, goalID string) []*models.Task {
+	tasks, _ := h.storage.GetUserTasks(userID)
+	archived, _ := h.storage.GetArchivedTasks(userID)
+
+	var linked []*models.Task
+	for _, task := range append(append([]*models.Task{}, tasks...), archived...) {
+		if task.GoalID == goalID {
+			linked = append(linked, task)
+		}
+	}
+	return linked
+}
+
+// syncGoalWithTasks пересчитывает шаги и прогресс цели по привязанным задачам.
+// Шаг с привязанными задачами выполнен, когда выполнены все его задачи.
+// Возвращает цель, если она только что оказалась достигнута.
+func (h *Handler) syncGoalWithTasks(userID, goalID string) *models.Goal {
+	if goalID == "" {
+		return nil
+	}
+
+	var goal *models.Goal
+	goals, _ := h.storage.GetUserGoals(userID)
+	for _, candidate := range goals {
+		if candidate.ID == goalID {
+			goal = candidate
+			break
+		}
+	}
+	if goal == nil {
+		return nil
+	}
+
+	linked := h.linkedTasks(userID, goalID)
+	for i, step := range goal.Steps {
+		stepTasks, stepDone := 0, 0
+		for _, task := range linked {
+			if task.GoalStepID == step.ID {
+				stepTasks++
+				if task.Completed {
+					stepDone++
+				}
+			}
+		}
+		if stepTasks > 0 {
+			goal.Steps[i].Completed = stepDone == stepTasks
+		}
+	}
+
+	wasCompleted, previousProgress := goal.Completed, goal.Progress
+	h.syncGoalProgress(goal)
+	if goal.Progress > previousProgress {
+		recordGoalCheckIn(goal, "выполнены задачи")
+	}
+
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		return nil
+	}
+	if goal.Completed && !wasCompleted {
+		return goal
+	}
+	return nil
+}
+
+// syncUserGoals пересчитывает все цели пользователя (после массовых изменений задач)
+func (h *Handler) syncUserGoals(userID string) {
+	goals, _ := h.storage.GetUserGoals(userID)
+	for _, goal := range goals {
+		h.syncGoalWithTasks(userID, goal.ID)
+	}
+}
+
+// goalTitles возвращает названия целей по их ID
+func (h *Handler) goalTitles(userID string) map[string]string {
+	goals, _ := h.storage.GetUserGoals(userID)
+	titles := make(map[string]string, len(goals))
+	for _, goal := range goals {
+		titles[goal.ID] = goal.Title
+	}
+	return titles
+}
+
+func tasksProgress(tasks []*models.Task) (int, int) {
+	done := 0
+	for _, task := range tasks {
+		if task.Completed {
+			done++
+		}
+	}
+	return done, len(tasks)
+}
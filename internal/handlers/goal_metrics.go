@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+)
+
+// ========== MEASURABLE GOALS ==========
+
+var (
+	goalAmountPattern = regexp.MustCompile(`(?:^|\s)(\d+(?:[.,]\d+)?)\s+([^\s\d]+)`)
+	// Срок, а не целевое значение: "за 3 месяца", "в 2025 году", "через 2 недели"
+	goalTimeUnitPattern  = regexp.MustCompile(`^(?:секунд|минут|час|дн|день|сут|недел|месяц|год|лет|г\.?$)`)
+	goalTimePrepositions = map[string]bool{"за": true, "в": true, "во": true, "к": true, "до": true, "через": true, "с": true, "со": true}
+	goalCheckInPattern   = regexp.MustCompile(`^цел\S*\s+(\d+)\s*([+=-])\s*(\d+(?:[.,]\d+)?)\s*(\S*)$`)
+	goalTargetPattern    = regexp.MustCompile(`^(?:установ\S*|зада\S*)\s+цел\S*\s+(\d+)\s+(\d+(?:[.,]\d+)?)\s+(\S+)$`)
+	goalHistoryRe        = regexp.MustCompile(`^истори\S*\s+цел\S*\s+(\d+)$`)
+)
+
+// checkInHistoryLimit - сколько последних отметок показывать в истории цели
+const checkInHistoryLimit = 10
+
+func isGoalMetricCommand(text string) bool {
+	return goalCheckInPattern.MatchString(text) || goalTargetPattern.MatchString(text) || goalHistoryRe.MatchString(text)
+}
+
+func (h *Handler) handleGoalMetricCommand(text, userID string) string {
+	switch {
+	case goalCheckInPattern.MatchString(text):
+		return h.checkInGoalValue(text, userID)
+	case goalTargetPattern.MatchString(text):
+		return h.setGoalTarget(text, userID)
+	default:
+		return h.goalHistory(text, userID)
+	}
+}
+
+// parseGoalTarget выделяет из названия цели целевое значение: "прочитать 12 книг" -> 12 "книг".
+// Сроки ("выучить английский за 3 месяца", "сдать сессию в 2025 году") целевым значением не считаются.
+func parseGoalTarget(title string) (float64, string, bool) {
+	for _, match := range goalAmountPattern.FindAllStringSubmatchIndex(title, -1) {
+		number, unit := title[match[2]:match[3]], title[match[4]:match[5]]
+		if goalTimeUnitPattern.MatchString(unit) {
+			continue
+		}
+		// Частота, а не целевое значение: "бегать 3 раза в неделю"
+		if strings.HasPrefix(unit, "раз") && strings.HasPrefix(strings.TrimSpace(title[match[5]:]), "в ") {
+			continue
+		}
+		if words := strings.Fields(title[:match[2]]); len(words) > 0 && goalTimePrepositions[words[len(words)-1]] {
+			continue
+		}
+		target, err := parseAmount(number)
+		if err != nil || target <= 0 {
+			continue
+		}
+		return target, unit, true
+	}
+	return 0, "", false
+}
+
+// setGoalTarget делает цель измеримой: "установить цель 2 100 км"
+func (h *Handler) setGoalTarget(text, userID string) string {
+	match := goalTargetPattern.FindStringSubmatch(text)
+	goal, ok := h.goalByNumber(userID, match[1])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+
+	target, err := parseAmount(match[2])
+	if err != nil || target <= 0 {
+		return "❌ Целевое значение должно быть больше нуля"
+	}
+
+	goal.Target = target
+	goal.Unit = match[3]
+	wasCompleted := goal.Completed
+	h.syncGoalProgress(goal)
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		return "❌ Ошибка при обновлении цели"
+	}
+
+	response := fmt.Sprintf("📏 Цель \"%s\": %s\n\nОтмечай продвижение: \"цель %s +5 %s\"", goal.Title, formatGoalValue(goal), match[1], goal.Unit)
+	if goal.Completed && !wasCompleted {
+		response += "\n\n" + h.goalCompletedMessage(goal)
+	}
+	return response
+}
+
+// checkInGoalValue меняет текущее значение измеримой цели: "цель 2 +5 км", "цель 2 = 40"
+func (h *Handler) checkInGoalValue(text, userID string) string {
+	match := goalCheckInPattern.FindStringSubmatch(text)
+	goal, ok := h.goalByNumber(userID, match[1])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+	if goal.Target <= 0 {
+		return fmt.Sprintf("❌ Цель \"%s\" не измеримая. Задай целевое значение: \"установить цель %s 100 км\"", goal.Title, match[1])
+	}
+	if match[4] != "" && match[4] != goal.Unit {
+		return fmt.Sprintf("❌ Цель измеряется в \"%s\"", goal.Unit)
+	}
+
+	amount, err := parseAmount(match[3])
+	if err != nil {
+		return "❌ Не понял число. Например: \"цель 2 +5 км\""
+	}
+
+	value := goal.Current
+	switch match[2] {
+	case "+":
+		value += amount
+	case "-":
+		value -= amount
+	default:
+		value = amount
+	}
+	if value < 0 {
+		return "❌ Значение не может быть отрицательным"
+	}
+
+	wasCompleted := goal.Completed
+	h.recordGoalValue(goal, value, "")
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		return "❌ Ошибка при обновлении цели"
+	}
+
+	if goal.Completed && !wasCompleted {
+		return h.goalCompletedMessage(goal)
+	}
+	return fmt.Sprintf("📈 \"%s\": %s\n%s %d%%", goal.Title, formatGoalValue(goal), h.createProgressBar(goal.Progress), goal.Progress)
+}
+
+// recordGoalValue выставляет значение измеримой цели и добавляет отметку в историю
+func (h *Handler) recordGoalValue(goal *models.Goal, value float64, note string) {
+	delta := value - goal.Current
+	goal.Current = value
+	h.syncGoalProgress(goal)
+	goal.CheckIns = append(goal.CheckIns, models.GoalCheckIn{
+		Date:     time.Now(),
+		Delta:    delta,
+		Value:    value,
+		Progress: goal.Progress,
+		Note:     note,
+	})
+}
+
+// recordGoalCheckIn добавляет в историю отметку о текущем прогрессе цели
+func recordGoalCheckIn(goal *models.Goal, note string) {
+	goal.CheckIns = append(goal.CheckIns, models.GoalCheckIn{
+		Date:     time.Now(),
+		Value:    goal.Current,
+		Progress: goal.Progress,
+		Note:     note,
+	})
+}
+
+func (h *Handler) goalHistory(text, userID string) string {
+	match := goalHistoryRe.FindStringSubmatch(text)
+	goal, ok := h.goalByNumber(userID, match[1])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+	if len(goal.CheckIns) == 0 {
+		return fmt.Sprintf("📜 По цели \"%s\" пока нет отметок", goal.Title)
+	}
+
+	checkIns := goal.CheckIns
+	if len(checkIns) > checkInHistoryLimit {
+		checkIns = checkIns[len(checkIns)-checkInHistoryLimit:]
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("📜 История цели \"%s\":\n\n", goal.Title))
+	for i := len(checkIns) - 1; i >= 0; i-- {
+		checkIn := checkIns[i]
+		response.WriteString(fmt.Sprintf("• %s: ", checkIn.Date.Format("02.01 15:04")))
+		if goal.Target > 0 {
+			response.WriteString(fmt.Sprintf("%s%s → %s %s (%d%%)", signOf(checkIn.Delta), formatAmount(math.Abs(checkIn.Delta)), formatAmount(checkIn.Value), goal.Unit, checkIn.Progress))
+		} else {
+			response.WriteString(fmt.Sprintf("%d%%", checkIn.Progress))
+		}
+		if checkIn.Note != "" {
+			response.WriteString(" - " + checkIn.Note)
+		}
+		response.WriteString("\n")
+	}
+	return response.String()
+}
+
+// formatGoalValue выводит значение измеримой цели: "40/100 км"
+func formatGoalValue(goal *models.Goal) string {
+	return fmt.Sprintf("%s/%s %s", formatAmount(goal.Current), formatAmount(goal.Target), goal.Unit)
+}
+
+func parseAmount(text string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+}
+
+func formatAmount(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func signOf(value float64) string {
+	if value < 0 {
+		return "-"
+	}
+	return "+"
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import "testing"
+
+func TestParseGoalTarget(t *testing.T) {
+	tests := []struct {
+		title  string
+		target float64
+		unit   string
+		ok     bool
+	}{
+		{"прочитать 12 книг", 12, "книг", true},
+		{"пробежать 100 км за 2 месяца", 100, "км", true},
+		{"выучить английский за 3 месяца", 0, "", false},
+		{"сдать сессию в 2025 году", 0, "", false},
+		{"за 2 месяца прочитать 5 книг", 5, "книг", true},
+		{"похудеть на 5,5 кг", 5.5, "кг", true},
+		{"бегать 3 раза в неделю", 0, "", false},
+		{"отжаться 100 раз", 100, "раз", true},
+		{"накопить 0 рублей", 0, "", false},
+		{"выучить python3 за лето", 0, "", false},
+		{"стать спокойнее", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			target, unit, ok := parseGoalTarget(tt.title)
+			if target != tt.target || unit != tt.unit || ok != tt.ok {
+				t.Errorf("parseGoalTarget() = %v, %q, %v, want %v, %q, %v", target, unit, ok, tt.target, tt.unit, tt.ok)
+			}
+		})
+	}
+}
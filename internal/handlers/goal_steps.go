@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== GOAL STEPS FUNCTIONALITY ==========
+
+var (
+	addStepPattern = regexp.MustCompile(`^добав\S*\s+шаг\S*\s+(?:к|в|для)\s+цел\S*\s+(\d+)\s+(.+)$`)
+	stepPattern    = regexp.MustCompile(`шаг\S*\s+(\d+)\s+(?:в\s+|из\s+|у\s+)?цел\S*\s+(\d+)(?:\s+на\s+(?:место\s+)?(\d+))?`)
+	stepsGoalRe    = regexp.MustCompile(`^шаг\S*\s+(?:к\s+|у\s+)?цел\S*\s+(\d+)$`)
+
+	goalProgressPattern = regexp.MustCompile(`цел\S*\s+(\d+)\s+(?:на\s+)?(-?\d+)\s*%?`)
+)
+
+func isGoalStepCommand(text string) bool {
+	return addStepPattern.MatchString(text) || stepPattern.MatchString(text) || stepsGoalRe.MatchString(text)
+}
+
+func (h *Handler) handleGoalStepCommand(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	switch {
+	case strings.HasPrefix(text, "добав"):
+		return h.addGoalSteps(text, userID)
+	case strings.HasPrefix(text, "удали"):
+		return h.deleteGoalStep(text, userID)
+	case strings.HasPrefix(text, "перемест") || strings.HasPrefix(text, "передвин"):
+		return h.moveGoalStep(text, userID)
+	case strings.HasPrefix(text, "отмет") || strings.HasPrefix(text, "выполни"):
+		return h.toggleGoalStep(ctx, api, text, userID, chatID)
+	default:
+		return h.showGoalSteps(ctx, api, text, userID, chatID)
+	}
+}
+
+// addGoalSteps добавляет один или несколько шагов (через запятую) к цели
+func (h *Handler) addGoalSteps(text, userID string) string {
+	match := addStepPattern.FindStringSubmatch(text)
+	if match == nil {
+		return "❌ Например: \"добавить шаг к цели 1 пройти курс\" (несколько шагов - через запятую)"
+	}
+
+	goal, ok := h.goalByNumber(userID, match[1])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+
+	added := 0
+	for _, stepText := range strings.Split(match[2], ",") {
+		stepText = strings.TrimSpace(stepText)
+		if stepText == "" {
+			continue
+		}
+		goal.Steps = append(goal.Steps, models.GoalStep{
+			ID:   newID(),
+			Text: stepText,
+		})
+		added++
+	}
+	if added == 0 {
+		return "❌ Текст шага не может быть пустым"
+	}
+
+	// Новый невыполненный шаг снижает прогресс и снова открывает цель
+	h.syncGoalProgress(goal)
+
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		return "❌ Ошибка при добавлении шага"
+	}
+
+	done, total := goalStepsProgress(goal)
+	return fmt.Sprintf("✅ Добавлено шагов: %d\n🪜 \"%s\": %d/%d\n\nИспользуй \"шаги цели %s\" чтобы отмечать шаги кнопками.",
+		added, goal.Title, done, total, match[1])
+}
+
+func (h *Handler) deleteGoalStep(text, userID string) string {
+	goal, index, _, ok := h.goalStepFromText(text, userID)
+	if !ok {
+		return "❌ Например: \"удалить шаг 2 цели 1\""
+	}
+
+	removed := goal.Steps[index]
+	goal.Steps = append(goal.Steps[:index], goal.Steps[index+1:]...)
+	wasCompleted := goal.Completed
+	h.syncGoalProgress(goal)
+
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		return "❌ Ошибка при удалении шага"
+	}
+
+	response := fmt.Sprintf("🗑 Шаг удален: \"%s\"", removed.Text)
+	if goal.Completed && !wasCompleted {
+		response += "\n\n" + h.goalCompletedMessage(goal)
+	}
+	return response
+}
+
+// moveGoalStep переставляет шаг на новую позицию: "переместить шаг 3 цели 1 на 1"
+func (h *Handler) moveGoalStep(text, userID string) string {
+	goal, index, target, ok := h.goalStepFromText(text, userID)
+	if !ok || target < 1 || target > len(goal.Steps) {
+		return "❌ Например: \"переместить шаг 3 цели 1 на 1\""
+	}
+
+	moveGoalStep(goal, index, target-1)
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		return "❌ Ошибка при перемещении шага"
+	}
+
+	return fmt.Sprintf("↕️ Шаг перемещен на позицию %d\n\n%s", target, formatGoalSteps(goal))
+}
+
+func (h *Handler) toggleGoalStep(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	goal, index, _, ok := h.goalStepFromText(text, userID)
+	if !ok {
+		return "❌ Например: \"выполнить шаг 2 цели 1\""
+	}
+
+	h.applyGoalStepToggle(ctx, api, goal, index, chatID)
+	return ""
+}
+
+func (h *Handler) showGoalSteps(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	match := stepsGoalRe.FindStringSubmatch(text)
+	if match == nil {
+		return "❌ Укажи номер цели. Например: \"шаги цели 1\""
+	}
+
+	goal, ok := h.goalByNumber(userID, match[1])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+	if len(goal.Steps) == 0 {
+		return fmt.Sprintf("🪜 У цели \"%s\" пока нет шагов.\n\nДобавь их: \"добавить шаг к цели %s [текст]\"", goal.Title, match[1])
+	}
+
+	h.sendGoalSteps(ctx, api, goal, chatID)
+	return ""
+}
+
+// handleGoalStepCallback обрабатывает кнопки шагов: goal_step_<goalID>_<stepID> и goal_stepup_<goalID>_<stepID>
+func (h *Handler) handleGoalStepCallback(ctx context.Context, api *maxbot.Api, userID string, chatID int64, payload string) {
+	action, ids, _ := strings.Cut(payload, "_")
+	goal, index, ok := h.goalStepByID(userID, ids)
+	if !ok {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("❌ Шаг не найден"))
+		return
+	}
+
+	switch action {
+	case "step":
+		h.applyGoalStepToggle(ctx, api, goal, index, chatID)
+	case "stepup":
+		if index > 0 {
+			moveGoalStep(goal, index, index-1)
+			h.storage.UpdateGoal(goal)
+		}
+		h.sendGoalSteps(ctx, api, goal, chatID)
+	}
+}
+
+// applyGoalStepToggle отмечает шаг и пересчитывает прогресс; когда выполнены все шаги, цель достигнута
+func (h *Handler) applyGoalStepToggle(ctx context.Context, api *maxbot.Api, goal *models.Goal, index int, chatID int64) {
+	goal.Steps[index].Completed = !goal.Steps[index].Completed
+	wasCompleted := goal.Completed
+	h.syncGoalProgress(goal)
+	if goal.Steps[index].Completed {
+		recordGoalCheckIn(goal, "шаг: "+goal.Steps[index].Text)
+	}
+
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("❌ Ошибка при обновлении шага"))
+		return
+	}
+
+	if goal.Completed && !wasCompleted {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(h.goalCompletedMessage(goal)))
+		return
+	}
+
+	h.sendGoalSteps(ctx, api, goal, chatID)
+}
+
+func (h *Handler) sendGoalSteps(ctx context.Context, api *maxbot.Api, goal *models.Goal, chatID int64) {
+	done, total := goalStepsProgress(goal)
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🪜 %s (%d/%d, %d%%)\n\n", goal.Title, done, total, goal.Progress))
+	response.WriteString(formatGoalSteps(goal))
+	response.WriteString("\nНажми на шаг чтобы отметить его, ⬆ - поднять шаг выше")
+
+	keyboard := api.Messages.NewKeyboardBuilder()
+	for i, step := range goal.Steps {
+		mark, intent := "⬜", schemes.DEFAULT
+		if step.Completed {
+			mark, intent = "✅", schemes.POSITIVE
+		}
+		row := keyboard.AddRow().AddCallback(fmt.Sprintf("%s %s", mark, step.Text), intent, fmt.Sprintf("goal_step_%s_%s", goal.ID, step.ID))
+		if i > 0 {
+			row.AddCallback("⬆", schemes.DEFAULT, fmt.Sprintf("goal_stepup_%s_%s", goal.ID, step.ID))
+		}
+	}
+
+	_, err := api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response.String()).AddKeyboard(keyboard))
+	if err = sendError(err); err != nil {
+		fmt.Printf("❌ Error sending message: %v\n", err)
+	}
+}
+
+// formatGoalSteps выводит шаги цели списком
+func formatGoalSteps(goal *models.Goal) string {
+	var response strings.Builder
+	for i, step := range goal.Steps {
+		mark := "⬜"
+		if step.Completed {
+			mark = "✅"
+		}
+		response.WriteString(fmt.Sprintf("%s %d. %s\n", mark, i+1, step.Text))
+	}
+	return response.String()
+}
+
+// moveGoalStep переносит шаг с позиции from на позицию to
+func moveGoalStep(goal *models.Goal, from, to int) {
+	step := goal.Steps[from]
+	steps := append(goal.Steps[:from:from], goal.Steps[from+1:]...)
+	steps = append(steps[:to], append([]models.GoalStep{step}, steps[to:]...)...)
+	goal.Steps = steps
+}
+
+// goalStepFromText находит цель и индекс шага по тексту вида "шаг 2 цели 1 [на 3]".
+// Третье значение - позиция после "на", если она указана.
+func (h *Handler) goalStepFromText(text, userID string) (*models.Goal, int, int, bool) {
+	match := stepPattern.FindStringSubmatch(text)
+	if match == nil {
+		return nil, 0, 0, false
+	}
+
+	goal, ok := h.goalByNumber(userID, match[2])
+	if !ok {
+		return nil, 0, 0, false
+	}
+
+	stepNumber, err := strconv.Atoi(match[1])
+	if err != nil || stepNumber < 1 || stepNumber > len(goal.Steps) {
+		return nil, 0, 0, false
+	}
+
+	target, _ := strconv.Atoi(match[3])
+	return goal, stepNumber - 1, target, true
+}
+
+func (h *Handler) goalStepByID(userID, ids string) (*models.Goal, int, bool) {
+	goalID, stepID, found := strings.Cut(ids, "_")
+	if !found {
+		return nil, 0, false
+	}
+
+	goals, _ := h.storage.GetUserGoals(userID)
+	for _, goal := range goals {
+		if goal.ID != goalID {
+			continue
+		}
+		for i, step := range goal.Steps {
+			if step.ID == stepID {
+				return goal, i, true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// goalByNumber возвращает цель по ее номеру в списке
+func (h *Handler) goalByNumber(userID, number string) (*models.Goal, bool) {
+	goals, _ := h.storage.GetUserGoals(userID)
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 || n > len(goals) {
+		return nil, false
+	}
+	return goals[n-1], true
+}
+
+// syncGoalProgress пересчитывает прогресс цели: у измеримой цели - по значению,
+// у цели с прогрессом по задачам - по выполненным привязанным задачам, у остальных - по шагам
+func (h *Handler) syncGoalProgress(goal *models.Goal) {
+	if goal.Target > 0 {
+		progress := int(goal.Current * 100 / goal.Target)
+		if progress > 100 {
+			progress = 100
+		}
+		h.setGoalProgress(goal, progress)
+		return
+	}
+
+	if goal.ProgressFromTasks {
+		if done, total := tasksProgress(h.linkedTasks(goal.UserID, goal.ID)); total > 0 {
+			h.setGoalProgress(goal, done*100/total)
+			return
+		}
+	}
+
+	done, total := goalStepsProgress(goal)
+	if total == 0 {
+		return
+	}
+	h.setGoalProgress(goal, done*100/total)
+}
+
+func goalStepsProgress(goal *models.Goal) (int, int) {
+	done := 0
+	for _, step := range goal.Steps {
+		if step.Completed {
+			done++
+		}
+	}
+	return done, len(goal.Steps)
+}
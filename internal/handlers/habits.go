@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== HABIT TRACKER ==========
+
+const (
+	habitDateLayout = "2006-01-02"
+
+	// habitReminderHour - час, когда бот напоминает о неотмеченных за день привычках
+	habitReminderHour = 20
+
+	// habitRateDays - за сколько последних дней считается процент выполнения
+	habitRateDays = 30
+)
+
+// habitCommandPattern - команды привычек: "привычки", "добавить привычку ...", "сделал привычку 1".
+// Слово "привычка" внутри текста задачи или цели командой не считается.
+var habitCommandPattern = regexp.MustCompile(`^(?:(?:мои|добав\S*|удали\S*|сделал\S*|отмет\S*|выполни\S*|календар\S*)\s+)?привычк`)
+
+func isHabitCommand(text string) bool {
+	return habitCommandPattern.MatchString(text)
+}
+
+func (h *Handler) handleHabitCommand(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	switch {
+	case strings.HasPrefix(text, "добав"):
+		return h.addHabit(text, userID)
+	case strings.HasPrefix(text, "удали"):
+		return h.deleteHabit(text, userID)
+	case strings.HasPrefix(text, "сделал") || strings.HasPrefix(text, "отмет") || strings.HasPrefix(text, "выполни"):
+		return h.markHabitByNumber(ctx, api, text, userID, chatID)
+	case strings.HasPrefix(text, "календар"):
+		return h.habitCalendar(text, userID)
+	default:
+		h.sendHabits(ctx, api, userID, chatID)
+		return ""
+	}
+}
+
+// addHabit добавляет привычку: "добавить привычку зарядка ежедневно", "добавить привычку бег по пн ср пт"
+func (h *Handler) addHabit(text, userID string) string {
+	_, title, _ := strings.Cut(text, "привычк")
+	_, title, _ = strings.Cut(title, " ")
+	title = strings.TrimSpace(title)
+
+	now := h.userNow(userID)
+	schedule := &models.Recurrence{Frequency: "daily"}
+	if rule, _, rest := parseRecurrence(title, now); rule != nil && rule.Frequency != "monthly" {
+		schedule, title = rule, strings.TrimSpace(rest)
+	}
+	if title == "" {
+		return "❌ Укажи привычку. Например: \"добавить привычку зарядка ежедневно\" или \"добавить привычку бег по пн ср пт\""
+	}
+
+	habit := &models.Habit{
+		ID:       newID(),
+		UserID:   userID,
+		Title:    title,
+		Created:  now,
+		Schedule: schedule,
+	}
+	if err := h.storage.SaveHabit(habit); err != nil {
+		return "❌ Ошибка при добавлении привычки"
+	}
+
+	return fmt.Sprintf("🌱 Привычка добавлена: \"%s\" (%s)\n\nОтмечай выполнение кнопками в списке \"привычки\".", habit.Title, describeRecurrence(habit.Schedule))
+}
+
+func (h *Handler) deleteHabit(text, userID string) string {
+	habits, _ := h.storage.GetUserHabits(userID)
+	number, _ := extractNumber(text, len(habits))
+	if number == 0 {
+		return "❌ Укажи номер привычки. Например: \"удалить привычку 1\""
+	}
+
+	habit := habits[number-1]
+	if err := h.storage.DeleteHabit(userID, habit.ID); err != nil {
+		return "❌ Ошибка при удалении привычки"
+	}
+	return fmt.Sprintf("🗑 Привычка удалена: \"%s\"", habit.Title)
+}
+
+func (h *Handler) markHabitByNumber(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	habits, _ := h.storage.GetUserHabits(userID)
+	number, _ := extractNumber(text, len(habits))
+	if number == 0 {
+		return "❌ Укажи номер привычки. Например: \"сделал привычку 1\""
+	}
+
+	return h.toggleHabitToday(habits[number-1], h.userNow(userID))
+}
+
+// handleHabitCallback обрабатывает кнопку "✅ сделал": habit_done_<habitID>
+func (h *Handler) handleHabitCallback(ctx context.Context, api *maxbot.Api, upd *schemes.MessageCallbackUpdate, userID string, chatID int64) {
+	habitID := strings.TrimPrefix(upd.Callback.Payload, "habit_done_")
+
+	habits, _ := h.storage.GetUserHabits(userID)
+	for _, habit := range habits {
+		if habit.ID == habitID {
+			response := h.toggleHabitToday(habit, h.userNow(userID))
+			api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+			h.sendHabits(ctx, api, userID, chatID)
+			return
+		}
+	}
+	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("❌ Привычка не найдена"))
+}
+
+// toggleHabitToday отмечает привычку выполненной сегодня; повторное нажатие снимает отметку
+func (h *Handler) toggleHabitToday(habit *models.Habit, now time.Time) string {
+	today := now.Format(habitDateLayout)
+
+	done := false
+	for i, date := range habit.DoneDates {
+		if date == today {
+			habit.DoneDates = append(habit.DoneDates[:i], habit.DoneDates[i+1:]...)
+			done = true
+			break
+		}
+	}
+	if !done {
+		habit.DoneDates = append(habit.DoneDates, today)
+	}
+
+	if err := h.storage.UpdateHabit(habit); err != nil {
+		return "❌ Ошибка при обновлении привычки"
+	}
+
+	if done {
+		return fmt.Sprintf("↩ Отметка за сегодня снята: \"%s\"", habit.Title)
+	}
+	streak, _ := habitStreaks(habit, now)
+	return fmt.Sprintf("✅ \"%s\" - сделано! Серия: %d 🔥", habit.Title, streak)
+}
+
+// sendHabits показывает привычки со статусом на сегодня и кнопками для отметки
+func (h *Handler) sendHabits(ctx context.Context, api *maxbot.Api, userID string, chatID int64) {
+	habits, _ := h.storage.GetUserHabits(userID)
+	if len(habits) == 0 {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("🌱 У тебя пока нет привычек!\n\nДобавь первую: \"добавить привычку зарядка ежедневно\""))
+		return
+	}
+
+	now := h.userNow(userID)
+	var response strings.Builder
+	response.WriteString("🌱 Твои привычки:\n\n")
+
+	keyboard := api.Messages.NewKeyboardBuilder()
+	hasButtons := false
+	for i, habit := range habits {
+		mark := "▫️"
+		if habitDoneOn(habit, now) {
+			mark = "✅"
+		} else if occursOn(habit.Schedule, now) {
+			mark = "⬜"
+			keyboard.AddRow().AddCallback("✅ Сделал: "+habit.Title, schemes.POSITIVE, "habit_done_"+habit.ID)
+			hasButtons = true
+		}
+
+		streak, best := habitStreaks(habit, now)
+		response.WriteString(fmt.Sprintf("%s %d. %s (%s)\n   🔥 Серия: %d (рекорд %d) · за %d дн.: %d%%\n",
+			mark, i+1, habit.Title, describeRecurrence(habit.Schedule), streak, best, habitRateDays, habitCompletionRate(habit, now)))
+	}
+	response.WriteString("\n✅ - сделано сегодня, ⬜ - ждет отметки, ▫️ - сегодня не нужно\n")
+	response.WriteString("Календарь: \"календарь привычки 1\"")
+
+	message := maxbot.NewMessage().SetChat(chatID).SetText(response.String())
+	if hasButtons {
+		message.AddKeyboard(keyboard)
+	}
+	if _, err := api.Messages.Send(ctx, message); sendError(err) != nil {
+		fmt.Printf("❌ Error sending message: %v\n", err)
+	}
+}
+
+// habitCalendar рисует календарь выполнения привычки за текущий месяц
+func (h *Handler) habitCalendar(text, userID string) string {
+	habits, _ := h.storage.GetUserHabits(userID)
+	number, _ := extractNumber(text, len(habits))
+	if number == 0 {
+		return "❌ Укажи номер привычки. Например: \"календарь привычки 1\""
+	}
+	habit := habits[number-1]
+
+	now := h.userNow(userID)
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	created := startOfDay(habit.Created)
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("📅 %s - %s\n\n", habit.Title, first.Format("01.2006")))
+	response.WriteString("пн вт ср чт пт сб вс\n")
+
+	// Пустые клетки до первого дня месяца (неделя начинается с понедельника)
+	offset := (int(first.Weekday()) + 6) % 7
+	response.WriteString(strings.Repeat("➖", offset))
+
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		switch {
+		case habitDoneOn(habit, day):
+			response.WriteString("✅")
+		case day.After(now) || day.Before(created) || !occursOn(habit.Schedule, day):
+			response.WriteString("⬜")
+		case sameDay(day, now):
+			response.WriteString("🔲")
+		default:
+			response.WriteString("❌")
+		}
+		if day.Weekday() == time.Sunday {
+			response.WriteString("\n")
+		}
+	}
+
+	streak, best := habitStreaks(habit, now)
+	response.WriteString(fmt.Sprintf("\n\n✅ сделано · ❌ пропущено · 🔲 сегодня · ⬜ не нужно\n🔥 Серия: %d · рекорд: %d · за %d дн.: %d%%",
+		streak, best, habitRateDays, habitCompletionRate(habit, now)))
+	return response.String()
+}
+
+// habitStreaks возвращает текущую и лучшую серии выполнения подряд (по дням расписания).
+// Неотмеченный сегодняшний день серию не прерывает.
+func habitStreaks(habit *models.Habit, now time.Time) (int, int) {
+	best, run := 0, 0
+	today := startOfDay(now)
+	for day := startOfDay(habit.Created); !day.After(today); day = day.AddDate(0, 0, 1) {
+		if !occursOn(habit.Schedule, day) {
+			continue
+		}
+		switch {
+		case habitDoneOn(habit, day):
+			run++
+		case day.Equal(today):
+			// сегодня еще можно успеть
+		default:
+			run = 0
+		}
+		if run > best {
+			best = run
+		}
+	}
+	return run, best
+}
+
+// habitCompletionRate - процент выполненных дней расписания за последние habitRateDays дней
+func habitCompletionRate(habit *models.Habit, now time.Time) int {
+	today := startOfDay(now)
+	from := today.AddDate(0, 0, -habitRateDays+1)
+	if created := startOfDay(habit.Created); created.After(from) {
+		from = created
+	}
+
+	due, done := 0, 0
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if !occursOn(habit.Schedule, day) {
+			continue
+		}
+		if habitDoneOn(habit, day) {
+			done++
+			due++
+		} else if !day.Equal(today) {
+			due++
+		}
+	}
+	if due == 0 {
+		return 0
+	}
+	return done * 100 / due
+}
+
+func habitDoneOn(habit *models.Habit, day time.Time) bool {
+	date := day.Format(habitDateLayout)
+	for _, done := range habit.DoneDates {
+		if done == date {
+			return true
+		}
+	}
+	return false
+}
+
+// processHabitReminders вечером напоминает о привычках, не отмеченных за сегодня
+func (h *Handler) processHabitReminders(ctx context.Context, api *maxbot.Api, now time.Time) {
+	users, _ := h.storage.GetUsers()
+	for _, user := range users {
+		// Напоминаем вечером по местному времени пользователя
+		now := now.In(h.userLocation(user.MAXUserID))
+		if now.Hour() < habitReminderHour {
+			continue
+		}
+		today := now.Format(habitDateLayout)
+
+		habits, _ := h.storage.GetUserHabits(user.MAXUserID)
+		for _, habit := range habits {
+			if habit.RemindedOn == today || !occursOn(habit.Schedule, now) || habitDoneOn(habit, now) {
+				continue
+			}
+
+			keyboard := api.Messages.NewKeyboardBuilder()
+			keyboard.AddRow().AddCallback("✅ Сделал", schemes.POSITIVE, "habit_done_"+habit.ID)
+			streak, _ := habitStreaks(habit, now)
+			text := fmt.Sprintf("🌱 Не забудь про привычку \"%s\"", habit.Title)
+			if streak > 0 {
+				text += fmt.Sprintf(" - не прерывай серию в %d дн. 🔥", streak)
+			}
+			if !h.notify(ctx, api, user.MAXUserID, notifyCheckIn, text, keyboard) {
+				continue
+			}
+
+			habit.RemindedOn = today
+			h.storage.UpdateHabit(habit)
+		}
+	}
+}
+
+// habitsSummary - сводка по привычкам для статистики
+func (h *Handler) habitsSummary(userID string, now time.Time) (int, int, int, int) {
+	habits, _ := h.storage.GetUserHabits(userID)
+	dueToday, doneToday, bestStreak := 0, 0, 0
+	for _, habit := range habits {
+		if occursOn(habit.Schedule, now) {
+			dueToday++
+			if habitDoneOn(habit, now) {
+				doneToday++
+			}
+		}
+		if streak, _ := habitStreaks(habit, now); streak > bestStreak {
+			bestStreak = streak
+		}
+	}
+	return len(habits), doneToday, dueToday, bestStreak
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/analytics"
+)
+
+// ========== PRODUCTIVITY INSIGHTS ==========
+
+// insightsMinSessions - меньше сессий для поиска закономерностей недостаточно
+const insightsMinSessions = 5
+
+// getInsights - самые продуктивные часы и дни, динамика прерываний и скорость выполнения задач
+func (h *Handler) getInsights(userID string) string {
+	insights := analytics.Analyze(h.analyticsInput(userID), h.userNow(userID))
+
+	if insights.Total.Sessions < insightsMinSessions && insights.CompletedTasks == 0 {
+		return fmt.Sprintf("🔍 Пока мало данных для инсайтов. Проведи хотя бы %d помодоро-сессий или выполни пару задач - и я расскажу, когда ты работаешь лучше всего!", insightsMinSessions)
+	}
+
+	var response strings.Builder
+	response.WriteString("🔍 Твои инсайты\n\n")
+
+	if insights.Total.Sessions >= insightsMinSessions {
+		if len(insights.BestHours) > 0 {
+			response.WriteString("⏰ Самые продуктивные часы:\n")
+			for _, hour := range insights.BestHours {
+				bucket := insights.ByHour[hour]
+				response.WriteString(fmt.Sprintf("• %02d:00-%02d:00 - %d мин. фокуса, завершено %d%% сессий\n", hour, (hour+1)%24, bucket.Minutes, bucket.CompletionRate()))
+			}
+		}
+
+		if len(insights.BestWeekdays) > 0 {
+			names := make([]string, len(insights.BestWeekdays))
+			for i, day := range insights.BestWeekdays {
+				names[i] = weekdayTitles[day]
+			}
+			response.WriteString(fmt.Sprintf("\n📅 Лучше всего работается по %s\n", strings.Join(names, " и ")))
+		}
+
+		response.WriteString(fmt.Sprintf("\n⚡ Прерываний: %d%% сессий (%d из %d)\n", insights.Total.InterruptionRate(), insights.Total.Interrupted, insights.Total.Sessions))
+		writeInterruptionTrend(&response, insights.Interruptions)
+		if hour, ok := worstInterruptionHour(insights); ok {
+			response.WriteString(fmt.Sprintf("Чаще всего отвлекаешься в %02d:00-%02d:00 - может, стоит планировать фокус на другое время\n", hour, (hour+1)%24))
+		}
+	} else {
+		response.WriteString(fmt.Sprintf("🍅 Про часы и прерывания расскажу после %d помодоро-сессий\n", insightsMinSessions))
+	}
+
+	if insights.CompletedTasks > 0 {
+		response.WriteString(fmt.Sprintf("\n📝 От создания задачи до выполнения в среднем проходит %s (выполнено задач: %d)", formatSpan(insights.AvgCompletion), insights.CompletedTasks))
+	}
+
+	if estimates := insights.Estimates.Total; estimates.Tasks > 0 {
+		response.WriteString(fmt.Sprintf("\n\n🍅 Оценки задач (%d): ", estimates.Tasks))
+		writeEstimateStats(&response, estimates)
+		response.WriteString("Подробнее: \"точность оценок\"")
+	}
+
+	return response.String()
+}
+
+// writeInterruptionTrend выводит долю прерванных сессий по неделям
+func writeInterruptionTrend(response *strings.Builder, weeks []analytics.WeekInterruptions) {
+	var rates []int
+	for _, week := range weeks {
+		if week.Sessions == 0 {
+			continue
+		}
+		response.WriteString(fmt.Sprintf("• %s-%s: %d%% (%d из %d)\n", week.Period.Start.Format("02.01"), week.Period.End.AddDate(0, 0, -1).Format("02.01"), week.InterruptionRate(), week.Interrupted, week.Sessions))
+		rates = append(rates, week.InterruptionRate())
+	}
+	if len(rates) < 2 {
+		return
+	}
+
+	first, last := rates[0], rates[len(rates)-1]
+	switch {
+	case last < first:
+		response.WriteString("📉 Прерываний становится меньше - так держать!\n")
+	case last > first:
+		response.WriteString("📈 Прерываний становится больше\n")
+	}
+}
+
+// worstInterruptionHour - час, в который прерывается наибольшая доля сессий
+func worstInterruptionHour(insights analytics.Insights) (int, bool) {
+	worst, found := 0, false
+	for hour, bucket := range insights.ByHour {
+		// Один-два случая - еще не закономерность
+		if bucket.Interrupted < 2 {
+			continue
+		}
+		if !found || bucket.InterruptionRate() > insights.ByHour[worst].InterruptionRate() {
+			worst, found = hour, true
+		}
+	}
+	return worst, found && insights.ByHour[worst].InterruptionRate() > insights.Total.InterruptionRate()
+}
+
+// formatSpan выводит длительность в днях, часах и минутах
+func formatSpan(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	days, hours := minutes/(24*60), minutes%(24*60)/60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d дн. %d ч.", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%d ч. %d мин.", hours, minutes%60)
+	default:
+		return fmt.Sprintf("%d мин.", minutes)
+	}
+}
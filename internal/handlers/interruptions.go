@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== INTERRUPTIONS ==========
+
+// interruptReasons - причины остановки сессии, которые предлагаем кнопками
+var interruptReasons = []struct {
+	key, title string
+}{
+	{"call", "📞 Звонок"},
+	{"people", "🗣 Отвлекли"},
+	{"urgent", "🔥 Срочное дело"},
+	{"tired", "😴 Устал"},
+	{"done", "✅ Закончил раньше"},
+}
+
+// interruptReasonTitle - название причины по ключу
+func interruptReasonTitle(key string) string {
+	for _, reason := range interruptReasons {
+		if reason.key == key {
+			return reason.title
+		}
+	}
+	return ""
+}
+
+// focusedMinutes - сколько полных минут пользователь успел поработать до остановки
+func focusedMinutes(session *models.PomodoroSession, stoppedAt time.Time) int {
+	minutes := int(stoppedAt.Sub(session.StartTime) / time.Minute)
+	return max(0, min(minutes, session.Duration))
+}
+
+// addInterruptReasons добавляет к клавиатуре кнопки выбора причины остановки
+func addInterruptReasons(keyboard *maxbot.Keyboard, sessionID string) {
+	for i := 0; i < len(interruptReasons); i += 2 {
+		row := keyboard.AddRow()
+		for _, reason := range interruptReasons[i:min(i+2, len(interruptReasons))] {
+			row.AddCallback(reason.title, schemes.DEFAULT, fmt.Sprintf("pomodoro_reason_%s_%s", sessionID, reason.key))
+		}
+	}
+}
+
+// setInterruptReason сохраняет причину остановки: pomodoro_reason_<sessionID>_<key>
+func (h *Handler) setInterruptReason(ctx context.Context, api *maxbot.Api, userID string, chatID int64, payload string) {
+	separator := strings.LastIndex(payload, "_")
+	if separator < 0 {
+		return
+	}
+	sessionID, key := payload[:separator], payload[separator+1:]
+
+	title := interruptReasonTitle(key)
+	if title == "" {
+		return
+	}
+
+	response := "❌ Сессия не найдена"
+	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
+	for _, session := range sessions {
+		if session.ID != sessionID {
+			continue
+		}
+		if !session.Interrupted {
+			response = "🤷 Эта сессия уже не остановлена"
+			break
+		}
+		session.InterruptReason = key
+		if err := h.storage.UpdatePomodoroSession(session); err != nil {
+			response = "❌ Ошибка при сохранении"
+			break
+		}
+		response = fmt.Sprintf("📝 Причина остановки: %s. Учту в статистике", title)
+		break
+	}
+	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+}
+
+// interruptionSummary - строка статистики о прерванных сессиях и самых частых причинах
+func (h *Handler) interruptionSummary(userID string) string {
+	interrupted := 0
+	counts := make(map[string]int)
+	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
+	for _, session := range sessions {
+		if session.Type != "work" || !session.Interrupted {
+			continue
+		}
+		interrupted++
+		if session.InterruptReason != "" {
+			counts[session.InterruptReason]++
+		}
+	}
+	if interrupted == 0 {
+		return "• Прерванных сессий: 0"
+	}
+
+	var reasons []string
+	for _, reason := range interruptReasons {
+		if counts[reason.key] > 0 {
+			reasons = append(reasons, reason.key)
+		}
+	}
+	sort.SliceStable(reasons, func(i, j int) bool { return counts[reasons[i]] > counts[reasons[j]] })
+
+	summary := fmt.Sprintf("• Прерванных сессий: %d", interrupted)
+	if len(reasons) == 0 {
+		return summary
+	}
+	parts := make([]string, len(reasons))
+	for i, key := range reasons {
+		parts[i] = fmt.Sprintf("%s - %d", interruptReasonTitle(key), counts[key])
+	}
+	return summary + "\n  Причины: " + strings.Join(parts, ", ")
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"proddy-bot/internal/analytics"
+	"proddy-bot/internal/gamification"
 	"proddy-bot/internal/models"
 	"proddy-bot/internal/storage"
 
@@ -16,17 +18,27 @@ import (
 
 // Handler структура для обработчиков
 type Handler struct {
-	storage        storage.MemoryStorage
-	activeTimers   map[string]*time.Timer // userID -> timer
-	pomodoroStatus map[string]string      // userID -> status
+	storage        *storage.MemoryStorage
+	activeTimers   map[string]*time.Timer          // userID -> timer
+	pomodoroStatus map[string]string               // userID -> status
+	undoActions    map[string][]*undoAction        // userID -> обратимые действия
+	confirmations  map[string]*confirmation        // token -> действие, ждущее подтверждения
+	rewards        map[string][]string             // userID -> поздравления, ждущие отправки
+	quietQueue     map[string][]queuedNotification // userID -> уведомления, отложенные до конца тихих часов
+	timerEvents    chan func()                     // сработавшие таймеры помодоро, ждущие главного цикла
 }
 
 // New создает новый экземпляр обработчика
-func New(storage storage.MemoryStorage) *Handler {
+func New(storage *storage.MemoryStorage) *Handler {
 	return &Handler{
 		storage:        storage,
 		activeTimers:   make(map[string]*time.Timer),
 		pomodoroStatus: make(map[string]string),
+		undoActions:    make(map[string][]*undoAction),
+		confirmations:  make(map[string]*confirmation),
+		rewards:        make(map[string][]string),
+		quietQueue:     make(map[string][]queuedNotification),
+		timerEvents:    make(chan func()),
 	}
 }
 
@@ -49,13 +61,19 @@ func (h *Handler) handleMessage(ctx context.Context, api *maxbot.Api, upd *schem
 	fmt.Printf("Message from %s: %s\n", upd.Message.Sender.FirstName, text)
 
 	// Регистрируем/обновляем пользователя
-	h.registerUser(upd.Message.Sender, userID)
+	h.registerUser(upd.Message.Sender, userID, chatID)
+	// Поздравления с достижениями приходят после ответа на команду
+	defer h.flushRewards(ctx, api, userID, chatID)
 
 	response := h.generateResponse(ctx, api, text, upd.Message.Sender.FirstName, userID, chatID)
+	if response == "" {
+		// Ответ уже отправлен обработчиком команды (например, с кнопками)
+		return
+	}
 
 	// Отправляем ответ
 	_, err := api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
-	if err != nil {
+	if err = sendError(err); err != nil {
 		fmt.Printf("❌ Error sending message: %v\n", err)
 	} else {
 		fmt.Printf("✅ Message sent successfully!\n")
@@ -63,7 +81,7 @@ func (h *Handler) handleMessage(ctx context.Context, api *maxbot.Api, upd *schem
 }
 
 // registerUser регистрирует или обновляет пользователя
-func (h *Handler) registerUser(sender schemes.User, userID string) {
+func (h *Handler) registerUser(sender schemes.User, userID string, chatID int64) {
 	user := &models.User{
 		ID:               userID,
 		MAXUserID:        userID,
@@ -71,6 +89,7 @@ func (h *Handler) registerUser(sender schemes.User, userID string) {
 		Username:         sender.Username,
 		RegistrationDate: time.Now(),
 		LastActivity:     time.Now(),
+		ChatID:           chatID,
 	}
 
 	existingUser, _ := h.storage.GetUser(userID)
@@ -80,6 +99,7 @@ func (h *Handler) registerUser(sender schemes.User, userID string) {
 		fmt.Printf("✅ New user registered: %s (%s)\n", sender.FirstName, userID)
 	} else {
 		// Обновляем активность существующего пользователя
+		existingUser.ChatID = chatID
 		h.storage.UpdateUserActivity(userID)
 	}
 }
@@ -89,12 +109,27 @@ func (h *Handler) handleCallback(ctx context.Context, api *maxbot.Api, upd *sche
 	// Обработка нажатий на кнопки
 	userID := fmt.Sprintf("%d", upd.Callback.GetUserID())
 	chatID := int64(upd.Callback.GetChatID())
+	if upd.Message != nil {
+		chatID = upd.Message.Recipient.ChatId
+	}
+	h.storage.UpdateUserActivity(userID)
+	defer h.flushRewards(ctx, api, userID, chatID)
 
 	switch {
 	case strings.HasPrefix(upd.Callback.Payload, "pomodoro_"):
 		h.handlePomodoroCallback(ctx, api, upd, userID, chatID)
 	case strings.HasPrefix(upd.Callback.Payload, "task_"):
 		h.handleTaskCallback(ctx, api, upd, userID, chatID)
+	case strings.HasPrefix(upd.Callback.Payload, "confirm_"):
+		h.handleConfirmCallback(ctx, api, upd, userID, chatID)
+	case strings.HasPrefix(upd.Callback.Payload, "undo_"):
+		h.handleUndoCallback(ctx, api, upd, userID, chatID)
+	case strings.HasPrefix(upd.Callback.Payload, "archive_"):
+		h.handleArchiveCallback(ctx, api, upd, userID, chatID)
+	case strings.HasPrefix(upd.Callback.Payload, "dialog_"):
+		h.handleDialogCallback(ctx, api, upd, userID, chatID)
+	case strings.HasPrefix(upd.Callback.Payload, "habit_"):
+		h.handleHabitCallback(ctx, api, upd, userID, chatID)
 	case strings.HasPrefix(upd.Callback.Payload, "goal_"):
 		h.handleGoalCallback(ctx, api, upd, userID, chatID)
 	default:
@@ -104,7 +139,9 @@ func (h *Handler) handleCallback(ctx context.Context, api *maxbot.Api, upd *sche
 		case "goals_list":
 			h.handleGoalsList(ctx, api, upd, userID)
 		case "stats":
-			h.handleStats(ctx, api, upd, userID)
+			h.sendStats(ctx, api, userID, chatID)
+		case "nudge_off":
+			api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(h.setInactivityDays("не напоминать", userID)))
 		}
 	}
 }
@@ -113,27 +150,93 @@ func (h *Handler) handleCallback(ctx context.Context, api *maxbot.Api, upd *sche
 func (h *Handler) generateResponse(ctx context.Context, api *maxbot.Api, text, userName, userID string, chatID int64) string {
 	text = strings.ToLower(strings.TrimSpace(text))
 
+	// Открытый диалог перехватывает ответы пользователя
+	if response, handled := h.handleDialogMessage(ctx, api, text, userID, chatID); handled {
+		return response
+	}
+
 	switch {
 	case text == "/start" || text == "start" || text == "начать":
 		return h.getWelcomeMessage(userName)
 
+	case distractionPattern.MatchString(text):
+		return h.logDistraction(text, userID)
+
 	case strings.Contains(text, "меню"):
 		return h.getMainMenu()
 
 	case strings.Contains(text, "помощь"):
-		return h.getHelpMessage()
+		return h.getHelpMessage(userID)
+
+	case isNotificationCommand(text):
+		return h.handleNotificationCommand(text, userID)
+
+	case isHabitCommand(text):
+		return h.handleHabitCommand(ctx, api, text, userID, chatID)
+
+	case strings.HasPrefix(text, "настройк"):
+		return h.startDialog(ctx, api, "settings", userID, chatID)
+
+	case isDigestCommand(text):
+		return h.handleDigestCommand(ctx, api, text, userID, chatID)
+
+	case strings.Contains(text, "неактивн"):
+		return h.setInactivityDays(text, userID)
+
+	case strings.Contains(text, "напоминать о цел"):
+		return h.setGoalStaleDays(text, userID)
+
+	case isReminderCommand(text):
+		return h.handleReminderCommand(text, userID)
+
+	case text == "отменить" || text == "отмена" || text == "↩ отменить":
+		return h.undoLast(ctx, api, userID, chatID)
+
+	case strings.Contains(text, "помодоро") || text == "перерыв":
+		return h.handlePomodoroCommand(ctx, api, text, userID, chatID)
 
 	case strings.Contains(text, "фокус") || strings.Contains(text, "pomodoro"):
 		return h.getPomodoroStatus(userID)
 
+	case isBulkCommand(text):
+		return h.handleBulkCommand(ctx, api, text, userID, chatID)
+
+	case isGoalLinkCommand(text):
+		return h.handleGoalLinkCommand(text, userID)
+
+	case isGoalStepCommand(text):
+		return h.handleGoalStepCommand(ctx, api, text, userID, chatID)
+
+	case goalDetailPattern.MatchString(text):
+		return h.editGoal(text, userID)
+
+	case isEstimateReportCommand(text):
+		return h.estimateReport(userID)
+
+	case isArchiveCommand(text):
+		return h.handleArchiveCommand(ctx, api, text, userID, chatID)
+
 	case strings.Contains(text, "задач") || strings.Contains(text, "дело"):
-		return h.handleTaskCommand(text, userID)
+		return h.handleTaskCommand(ctx, api, text, userID, chatID)
 
 	case strings.Contains(text, "цел"):
-		return h.handleGoalCommand(text, userID)
+		return h.handleGoalCommand(ctx, api, text, userID, chatID)
+
+	case strings.Contains(text, "достижени") || text == "уровень" || text == "опыт":
+		return h.getAchievements(userID)
+
+	case strings.Contains(text, "инсайт"):
+		return h.getInsights(userID)
+
+	case strings.Contains(text, "стат") && strings.Contains(text, "недел"):
+		return h.periodReport(userID, false)
+
+	case strings.Contains(text, "стат") && strings.Contains(text, "месяц"):
+		return h.periodReport(userID, true)
 
 	case strings.Contains(text, "стат"):
-		return h.getStats(userID)
+		h.sendStats(ctx, api, userID, chatID)
+		return ""
 
 	default:
 		return "🤔 Не совсем понял что ты имеешь в виду. Попробуй написать \"меню\" чтобы увидеть все возможности или \"помощь\" для справки!"
@@ -149,6 +252,7 @@ func (h *Handler) getPomodoroStatus(userID string) string {
 	if status == "" {
 		status = "не активен"
 	}
+	workDuration, breakDuration := h.pomodoroDurations(userID)
 
 	return fmt.Sprintf(`🎯 Режим фокуса (Pomodoro)
 
@@ -159,21 +263,53 @@ func (h *Handler) getPomodoroStatus(userID string) string {
 • Текущий статус: %s
 
 Команды:
-• "старт помодоро" - начать сессию (25 мин)
+• "старт помодоро" - начать сессию (%d мин)
+• "старт помодоро 2" - сессия над задачей 2 из списка
 • "стоп помодоро" - завершить сессию
-• "перерыв" - начать перерыв (5 мин)`,
+• "перерыв" - начать перерыв (%d мин)
+• "отвлёкся: [мысль]" - записать отвлечение, не прерывая сессию
+• "настройки" - изменить длительность`,
 		stats.TotalSessions,
 		stats.CompletedToday,
 		stats.TotalFocusTime,
-		status)
+		status,
+		workDuration,
+		breakDuration)
+}
+
+func (h *Handler) handlePomodoroCommand(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	switch {
+	case strings.Contains(text, "старт") || strings.Contains(text, "начать"):
+		task, problem := h.pomodoroTask(text, userID)
+		if problem != "" {
+			return problem
+		}
+		h.startPomodoro(ctx, api, userID, chatID, task)
+	case strings.Contains(text, "стоп") || strings.Contains(text, "останов"):
+		h.stopPomodoro(ctx, api, userID, chatID)
+	case strings.Contains(text, "перерыв"):
+		h.startBreak(ctx, api, userID, chatID)
+	default:
+		return h.getPomodoroStatus(userID)
+	}
+	return ""
 }
 
 func (h *Handler) handlePomodoroCallback(ctx context.Context, api *maxbot.Api, upd *schemes.MessageCallbackUpdate, userID string, chatID int64) {
 	payload := upd.Callback.Payload
 
+	if strings.HasPrefix(payload, "pomodoro_distraction_") {
+		h.distractionToTask(ctx, api, userID, chatID, strings.TrimPrefix(payload, "pomodoro_distraction_"))
+		return
+	}
+	if strings.HasPrefix(payload, "pomodoro_reason_") {
+		h.setInterruptReason(ctx, api, userID, chatID, strings.TrimPrefix(payload, "pomodoro_reason_"))
+		return
+	}
+
 	switch payload {
 	case "pomodoro_start":
-		h.startPomodoro(ctx, api, userID, chatID)
+		h.startPomodoro(ctx, api, userID, chatID, nil)
 	case "pomodoro_stop":
 		h.stopPomodoro(ctx, api, userID, chatID)
 	case "pomodoro_break":
@@ -181,33 +317,34 @@ func (h *Handler) handlePomodoroCallback(ctx context.Context, api *maxbot.Api, u
 	}
 }
 
-func (h *Handler) startPomodoro(ctx context.Context, api *maxbot.Api, userID string, chatID int64) {
-	// Останавливаем предыдущий таймер если есть
-	if timer, exists := h.activeTimers[userID]; exists {
-		timer.Stop()
-	}
-
-	h.pomodoroStatus[userID] = "работа ⏰ 25 мин"
+// startPomodoro начинает рабочую сессию; если указана задача, сессия засчитывается в ее оценку
+func (h *Handler) startPomodoro(ctx context.Context, api *maxbot.Api, userID string, chatID int64, task *models.Task) {
+	workDuration, _ := h.pomodoroDurations(userID)
+	h.pomodoroStatus[userID] = fmt.Sprintf("работа ⏰ %d мин", workDuration)
 
 	session := &models.PomodoroSession{
 		ID:        fmt.Sprintf("%d", time.Now().Unix()),
 		UserID:    userID,
 		StartTime: time.Now(),
-		Duration:  25,
+		Duration:  workDuration,
 		Type:      "work",
 		Completed: false,
 	}
+	if task != nil {
+		session.TaskID = task.ID
+	}
 
 	h.storage.SavePomodoroSession(session)
 
-	// Создаем таймер на 25 минут
-	timer := time.AfterFunc(25*time.Minute, func() {
+	// Создаем таймер на длительность сессии (предыдущий таймер, если есть, останавливается)
+	h.startTimer(userID, time.Duration(workDuration)*time.Minute, func() {
 		h.completePomodoro(ctx, api, userID, chatID, session.ID)
 	})
 
-	h.activeTimers[userID] = timer
-
-	response := "🎯 Pomodoro сессия началась!\n⏰ 25 минут фокуса...\n\nСосредоточься на задаче! 💪"
+	response := fmt.Sprintf("🎯 Pomodoro сессия началась!\n⏰ %d минут фокуса...\n\nСосредоточься на задаче! 💪", workDuration)
+	if task != nil {
+		response += "\n\n" + taskPomodoroProgress(task, h.taskPomodoros(userID)[task.ID])
+	}
 	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
 }
 
@@ -219,51 +356,125 @@ func (h *Handler) stopPomodoro(ctx context.Context, api *maxbot.Api, userID stri
 
 	h.pomodoroStatus[userID] = "остановлен"
 
+	response := "🛑 Pomodoro сессия остановлена\n\nМожешь начать заново когда будешь готов!"
+
 	// Помечаем сессию как прерванную
 	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
 	if len(sessions) > 0 {
 		lastSession := sessions[len(sessions)-1]
-		if !lastSession.Completed {
+		if !lastSession.Completed && !lastSession.Interrupted {
 			lastSession.Interrupted = true
 			lastSession.EndTime = time.Now()
-			// Здесь нужно обновить сессию в storage
+			if lastSession.Type == "work" {
+				// Засчитываем минуты, которые пользователь успел поработать
+				lastSession.FocusedMinutes = focusedMinutes(lastSession, lastSession.EndTime)
+				stats, _ := h.storage.GetPomodoroStats(userID)
+				stats.TotalFocusTime += lastSession.FocusedMinutes
+				h.storage.UpdatePomodoroStats(stats)
+				response = fmt.Sprintf("🛑 Pomodoro сессия остановлена\n⏱ Засчитано %d мин. фокуса\n\nЧто помешало? Можешь начать заново когда будешь готов!", lastSession.FocusedMinutes)
+			}
+			h.storage.UpdatePomodoroSession(lastSession)
+
+			token := h.pushUndo(userID, "остановка помодоро", func(ctx context.Context, api *maxbot.Api, chatID int64) string {
+				return h.resumePomodoro(ctx, api, userID, chatID, lastSession)
+			})
+			keyboard := undoKeyboard(api, token)
+			if lastSession.Type == "work" {
+				addInterruptReasons(keyboard, lastSession.ID)
+			}
+			message := maxbot.NewMessage().SetChat(chatID).SetText(response).AddKeyboard(keyboard)
+			if _, err := api.Messages.Send(ctx, message); sendError(err) != nil {
+				fmt.Printf("❌ Error sending message: %v\n", err)
+			}
+
+			if offer, keyboard := h.distractionsOffer(api, lastSession); offer != "" {
+				api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(offer).AddKeyboard(keyboard))
+			}
+			return
 		}
 	}
 
-	response := "🛑 Pomodoro сессия остановлена\n\nМожешь начать заново когда будешь готов!"
 	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
 }
 
-func (h *Handler) startBreak(ctx context.Context, api *maxbot.Api, userID string, chatID int64) {
-	// Останавливаем предыдущий таймер если есть
-	if timer, exists := h.activeTimers[userID]; exists {
-		timer.Stop()
+// resumePomodoro продолжает остановленную сессию с оставшимся временем (отмена остановки)
+func (h *Handler) resumePomodoro(ctx context.Context, api *maxbot.Api, userID string, chatID int64, session *models.PomodoroSession) string {
+	if _, running := h.activeTimers[userID]; running {
+		return "❌ Сейчас уже идет другой таймер - остановленную сессию не вернуть"
 	}
 
-	h.pomodoroStatus[userID] = "перерыв ☕ 5 мин"
+	// Частично засчитанные минуты вернутся полностью, когда сессия завершится
+	if session.FocusedMinutes > 0 {
+		stats, _ := h.storage.GetPomodoroStats(userID)
+		stats.TotalFocusTime = max(0, stats.TotalFocusTime-session.FocusedMinutes)
+		h.storage.UpdatePomodoroStats(stats)
+	}
+	session.Interrupted = false
+	session.EndTime = time.Time{}
+	session.FocusedMinutes = 0
+	session.InterruptReason = ""
+	h.storage.UpdatePomodoroSession(session)
+
+	remaining := time.Until(session.StartTime.Add(time.Duration(session.Duration) * time.Minute))
+	if remaining <= 0 {
+		h.completePomodoro(ctx, api, userID, chatID, session.ID)
+		return ""
+	}
 
-	// Создаем таймер на 5 минут
-	timer := time.AfterFunc(5*time.Minute, func() {
-		h.completeBreak(ctx, api, userID, chatID)
+	minutes := int(remaining.Round(time.Minute) / time.Minute)
+	h.pomodoroStatus[userID] = fmt.Sprintf("работа ⏰ осталось %d мин", minutes)
+	h.startTimer(userID, remaining, func() {
+		h.completePomodoro(ctx, api, userID, chatID, session.ID)
 	})
 
-	h.activeTimers[userID] = timer
+	return fmt.Sprintf("↩ Сессия продолжается! ⏰ Осталось %d мин.", minutes)
+}
 
-	response := "☕ Время перерыва!\n⏰ 5 минут отдыха...\n\nРасслабься и отдохни! 😊"
+func (h *Handler) startBreak(ctx context.Context, api *maxbot.Api, userID string, chatID int64) {
+	_, breakDuration := h.pomodoroDurations(userID)
+	h.pomodoroStatus[userID] = fmt.Sprintf("перерыв ☕ %d мин", breakDuration)
+
+	// Создаем таймер на длительность перерыва (предыдущий таймер, если есть, останавливается)
+	h.startTimer(userID, time.Duration(breakDuration)*time.Minute, func() {
+		h.completeBreak(ctx, api, userID, chatID)
+	})
+
+	response := fmt.Sprintf("☕ Время перерыва!\n⏰ %d минут отдыха...\n\nРасслабься и отдохни! 😊", breakDuration)
 	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
 }
 
+// startTimer заменяет таймер пользователя новым. Сам таймер срабатывает в отдельной горутине,
+// поэтому fire передается в главный цикл (см. Timers) и выполняется там, только если
+// таймер к этому моменту не остановили и не заменили.
+func (h *Handler) startTimer(userID string, duration time.Duration, fire func()) {
+	if timer, exists := h.activeTimers[userID]; exists {
+		timer.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(duration, func() {
+		h.timerEvents <- func() {
+			if h.activeTimers[userID] == timer {
+				fire()
+			}
+		}
+	})
+	h.activeTimers[userID] = timer
+}
+
 func (h *Handler) completePomodoro(ctx context.Context, api *maxbot.Api, userID string, chatID int64, sessionID string) {
 	delete(h.activeTimers, userID)
 	h.pomodoroStatus[userID] = "завершен"
 
 	// Обновляем сессию как завершенную
+	var completed *models.PomodoroSession
 	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
 	for _, session := range sessions {
 		if session.ID == sessionID {
 			session.Completed = true
 			session.EndTime = time.Now()
-			// Здесь нужно обновить сессию в storage
+			h.storage.UpdatePomodoroSession(session)
+			completed = session
 			break
 		}
 	}
@@ -272,11 +483,40 @@ func (h *Handler) completePomodoro(ctx context.Context, api *maxbot.Api, userID
 	stats, _ := h.storage.GetPomodoroStats(userID)
 	stats.TotalSessions++
 	stats.CompletedToday++
-	stats.TotalFocusTime += 25
+	if completed != nil {
+		stats.TotalFocusTime += completed.Duration
+	}
+	stats.CurrentStreak = analytics.FocusStreak(sessions, time.Now())
 	h.storage.UpdatePomodoroStats(stats)
 
+	h.emitEvent(userID, gamification.PomodoroCompleted, sessionID)
+
+	// Таймер срабатывает без участия пользователя, поэтому это уведомление;
+	// поздравления с достижениями отправляем вместе с ним
 	response := "✅ Pomodoro сессия завершена!\n\nОтличная работа! 🎉\n\nХочешь начать перерыв?"
-	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+	if completed != nil && completed.TaskID != "" {
+		tasks, _ := h.storage.GetUserTasks(userID)
+		for _, task := range tasks {
+			if task.ID == completed.TaskID {
+				response += "\n\n" + taskPomodoroProgress(task, h.taskPomodoros(userID)[task.ID])
+			}
+		}
+	}
+	rewards := h.takeRewards(userID)
+	if len(rewards) > 0 {
+		response += "\n\n" + strings.Join(rewards, "\n")
+	}
+	var keyboard *maxbot.Keyboard
+	if completed != nil {
+		if offer, offerKeyboard := h.distractionsOffer(api, completed); offer != "" {
+			response += "\n\n" + offer
+			keyboard = offerKeyboard
+		}
+	}
+	if !h.notify(ctx, api, userID, notifyPomodoro, response, keyboard) {
+		// Поздравления не дошли - отправим их со следующим ответом
+		h.addRewards(userID, rewards...)
+	}
 }
 
 func (h *Handler) completeBreak(ctx context.Context, api *maxbot.Api, userID string, chatID int64) {
@@ -284,21 +524,32 @@ func (h *Handler) completeBreak(ctx context.Context, api *maxbot.Api, userID str
 	h.pomodoroStatus[userID] = "перерыв завершен"
 
 	response := "✅ Перерыв завершен!\n\nГотов к новой сессии фокуса? 🚀"
-	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+	h.notify(ctx, api, userID, notifyPomodoro, response, nil)
 }
 
 // ========== TASK FUNCTIONALITY ==========
 
-func (h *Handler) handleTaskCommand(text, userID string) string {
+func (h *Handler) handleTaskCommand(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
 	text = strings.ToLower(text)
 
 	switch {
+	case strings.Contains(text, "повторяющ") || strings.HasPrefix(text, "останов"):
+		if strings.HasPrefix(text, "останов") {
+			return h.stopRecurringTask(text, userID)
+		}
+		return h.listRecurringTasks(userID)
+	case isChecklistCommand(text):
+		return h.handleChecklistCommand(ctx, api, text, userID, chatID)
+	case strings.HasPrefix(text, "измени"):
+		return h.editTask(text, userID)
+	case strings.Contains(text, "истори") && strings.Contains(text, "задач"):
+		return h.taskHistory(text, userID)
 	case strings.Contains(text, "добав") && strings.Contains(text, "задач"):
-		return h.addTask(text, userID)
+		return h.addTask(ctx, api, text, userID, chatID)
 	case strings.Contains(text, "удали") && strings.Contains(text, "задач"):
-		return h.deleteTask(text, userID)
+		return h.deleteTask(ctx, api, text, userID, chatID)
 	case strings.Contains(text, "выполни") && strings.Contains(text, "задач"):
-		return h.completeTask(text, userID)
+		return h.completeTask(ctx, api, text, userID, chatID)
 	case strings.Contains(text, "список") && strings.Contains(text, "задач"):
 		return h.listTasks(userID)
 	default:
@@ -306,40 +557,75 @@ func (h *Handler) handleTaskCommand(text, userID string) string {
 	}
 }
 
-func (h *Handler) addTask(text, userID string) string {
-	// Извлекаем описание задачи из текста
-	parts := strings.Split(text, "задач")
-	if len(parts) > 1 && len(parts[1]) > 0 {
-		parts[1] = parts[1][1:]
-	}
-	if len(parts) < 2 {
-		return "❌ Укажи описание задачи. Например: \"добавить задачу прочитать книгу\""
+// taskDescriptionFromText извлекает описание задачи из команды "добавить задачу ..."
+func taskDescriptionFromText(text string) string {
+	parts := strings.SplitN(text, "задач", 2)
+	if len(parts) < 2 || len(parts[1]) == 0 {
+		return ""
 	}
+	_, description, _ := strings.Cut(parts[1], " ")
+	return strings.TrimSpace(description)
+}
 
-	taskDescription := strings.TrimSpace(parts[1])
+func (h *Handler) addTask(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	taskDescription := taskDescriptionFromText(text)
 	if taskDescription == "" {
-		return "❌ Описание задачи не может быть пустым"
+		// Описание не указано - спрашиваем его по шагам
+		return h.startDialog(ctx, api, "add_task", userID, chatID)
 	}
 
+	task := newTask(userID, taskDescription, h.userNow(userID))
+	err := h.storage.SaveTask(task)
+	if err != nil {
+		return "❌ Ошибка при добавлении задачи"
+	}
+
+	return taskAddedMessage(task)
+}
+
+// newTask создает задачу с настройками по умолчанию. Правило повторения
+// ("стендап каждый день в 10:00") и оценка в помодоро ("~3🍅") выделяются из описания;
+// время в правиле - местное время пользователя (now).
+func newTask(userID, description string, now time.Time) *models.Task {
+	estimate, description := parseEstimate(description)
 	task := &models.Task{
-		ID:        fmt.Sprintf("%d", time.Now().Unix()),
+		ID:        newID(),
 		UserID:    userID,
-		Text:      taskDescription,
-		Created:   time.Now(),
+		Text:      description,
+		Created:   now,
 		Completed: false,
 		Priority:  "medium",
 		Category:  "personal",
+		Estimate:  estimate,
 	}
 
-	err := h.storage.SaveTask(task)
-	if err != nil {
-		return "❌ Ошибка при добавлении задачи"
+	if rule, clock, rest := parseRecurrence(description, task.Created); rule != nil && rest != "" {
+		deadline := firstOccurrence(rule, task.Created, clock)
+		task.Text = rest
+		task.SeriesID = newID()
+		task.Recurrence = rule
+		task.Deadline = &deadline
 	}
+	return task
+}
 
-	return fmt.Sprintf("✅ Задача добавлена: \"%s\"\n\nИспользуй \"список задач\" чтобы посмотреть все задачи.", taskDescription)
+func taskAddedMessage(task *models.Task) string {
+	if task.Recurrence != nil {
+		return fmt.Sprintf("🔁 Повторяющаяся задача добавлена: \"%s\" (%s)\nБлижайший срок: %s\n\nИспользуй \"повторяющиеся задачи\" чтобы посмотреть все серии.",
+			task.Text, describeRecurrence(task.Recurrence), formatWhen(*task.Deadline))
+	}
+
+	response := fmt.Sprintf("✅ Задача добавлена: \"%s\"", task.Text)
+	if task.Deadline != nil {
+		response += fmt.Sprintf("\n⏰ Срок: %s", formatWhen(*task.Deadline))
+	}
+	if task.Estimate > 0 {
+		response += fmt.Sprintf("\n🍅 Оценка: %s. Запусти \"старт помодоро [номер задачи]\", чтобы сессии засчитывались в нее", formatEstimate(task.Estimate))
+	}
+	return response + "\n\nИспользуй \"список задач\" чтобы посмотреть все задачи."
 }
 
-func (h *Handler) deleteTask(text, userID string) string {
+func (h *Handler) deleteTask(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
 	tasks, _ := h.storage.GetUserTasks(userID)
 	if len(tasks) == 0 {
 		return "📝 У тебя пока нет задач для удаления!"
@@ -360,15 +646,16 @@ func (h *Handler) deleteTask(text, userID string) string {
 	}
 
 	taskToDelete := tasks[taskNumber-1]
-	err := h.storage.DeleteTask(userID, taskToDelete.ID)
+	token, err := h.deleteTaskWithUndo(taskToDelete)
 	if err != nil {
 		return "❌ Ошибка при удалении задачи"
 	}
 
-	return fmt.Sprintf("✅ Задача удалена: \"%s\"", taskToDelete.Text)
+	h.sendWithUndo(ctx, api, chatID, fmt.Sprintf("✅ Задача удалена: \"%s\"", taskToDelete.Text), token)
+	return ""
 }
 
-func (h *Handler) completeTask(text, userID string) string {
+func (h *Handler) completeTask(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
 	tasks, _ := h.storage.GetUserTasks(userID)
 	if len(tasks) == 0 {
 		return "📝 У тебя пока нет задач!"
@@ -389,10 +676,23 @@ func (h *Handler) completeTask(text, userID string) string {
 	}
 
 	taskToComplete := tasks[taskNumber-1]
-	taskToComplete.Completed = true
-	// Здесь нужно обновить задачу в storage
+	if taskToComplete.Completed {
+		return fmt.Sprintf("✅ Задача уже выполнена: \"%s\"", taskToComplete.Text)
+	}
+	token, achieved, err := h.completeTaskWithUndo(taskToComplete)
+	if err != nil {
+		return "❌ Ошибка при обновлении задачи"
+	}
 
-	return fmt.Sprintf("✅ Задача выполнена: \"%s\"\n\nОтличная работа! 🎉", taskToComplete.Text)
+	response := fmt.Sprintf("✅ Задача выполнена: \"%s\"\n\nОтличная работа! 🎉", taskToComplete.Text)
+	if result := h.estimateResult(taskToComplete); result != "" {
+		response += "\n" + result
+	}
+	if achieved != nil {
+		response += "\n\n" + h.goalCompletedMessage(achieved)
+	}
+	h.sendWithUndo(ctx, api, chatID, response, token)
+	return ""
 }
 
 func (h *Handler) listTasks(userID string) string {
@@ -402,6 +702,8 @@ func (h *Handler) listTasks(userID string) string {
 		return "📝 У тебя пока нет задач!\n\nДобавь первую задачу написав \"добавить задачу [описание]\""
 	}
 
+	goalTitles := h.goalTitles(userID)
+	pomodoros := h.taskPomodoros(userID)
 	var response strings.Builder
 	response.WriteString("📝 Твои задачи:\n\n")
 
@@ -419,20 +721,251 @@ func (h *Handler) listTasks(userID string) string {
 		case "low":
 			priorityIcon = "🟢"
 		}
-		response.WriteString(fmt.Sprintf("%s%s %d. %s\n", status, priorityIcon, i+1, task.Text))
+		response.WriteString(fmt.Sprintf("%s%s %d. %s", status, priorityIcon, i+1, task.Text))
+		if task.Deadline != nil {
+			response.WriteString(fmt.Sprintf(" ⏰ %s", formatWhen(*task.Deadline)))
+		}
+		if task.Recurrence != nil {
+			response.WriteString(" 🔁")
+		}
+		if len(task.Items) > 0 {
+			done, total := checklistProgress(task)
+			response.WriteString(fmt.Sprintf(" ☑️ %d/%d", done, total))
+		}
+		if task.Estimate > 0 {
+			response.WriteString(fmt.Sprintf(" 🍅 %d/%s", pomodoros[task.ID], formatEstimate(task.Estimate)))
+		} else if pomodoros[task.ID] > 0 {
+			response.WriteString(fmt.Sprintf(" 🍅 %d", pomodoros[task.ID]))
+		}
+		if len(task.History) > 0 {
+			response.WriteString(" ✏️")
+		}
+		if goalTitle, linked := goalTitles[task.GoalID]; linked {
+			response.WriteString(fmt.Sprintf(" 🎯 %s", goalTitle))
+		}
+		response.WriteString("\n")
 	}
 
-	response.WriteString("\nКоманды:\n• \"выполнить задачу 1\" - отметить как выполненную\n• \"удалить задачу 1\" - удалить задачу")
+	response.WriteString("\nКоманды:\n• \"выполнить задачу 1\" - отметить как выполненную\n• \"изменить задачу 1 [текст]\" - изменить задачу\n• \"удалить задачу 1\" - удалить задачу")
 
 	return response.String()
 }
 
+var priorityNames = map[string]string{
+	"высокий": "high",
+	"средний": "medium",
+	"низкий":  "low",
+}
+
+var priorityTitles = map[string]string{
+	"high":   "высокий",
+	"medium": "средний",
+	"low":    "низкий",
+}
+
+var categoryNames = map[string]string{
+	"учеба":  "study",
+	"учёба":  "study",
+	"работа": "work",
+	"личное": "personal",
+}
+
+var categoryTitles = map[string]string{
+	"study":    "учеба",
+	"work":     "работа",
+	"personal": "личное",
+}
+
+// newID генерирует идентификатор сущности
+func newID() string {
+	return fmt.Sprintf("%d", time.Now().UnixNano())
+}
+
+// markTaskCompleted отмечает задачу выполненной и сохраняет ее.
+// Для повторяющейся задачи сразу создается и возвращается следующий экземпляр серии.
+// Вторым значением возвращается цель, которая достигнута выполнением этой задачи.
+func (h *Handler) markTaskCompleted(task *models.Task) (*models.Task, *models.Goal, error) {
+	now := time.Now()
+	task.Completed = true
+	task.CompletedAt = &now
+	if err := h.storage.UpdateTask(task); err != nil {
+		return nil, nil, err
+	}
+	h.emitEvent(task.UserID, gamification.TaskCompleted, task.ID)
+
+	achieved := h.syncGoalWithTasks(task.UserID, task.GoalID)
+
+	if task.Recurrence != nil {
+		tasks, _ := h.storage.GetUserTasks(task.UserID)
+		if latest, ok := seriesLatest(tasks)[task.SeriesID]; ok {
+			return h.ensureNextOccurrence(latest, tasks, now), achieved, nil
+		}
+	}
+	return nil, achieved, nil
+}
+
+// extractNumber ищет в тексте номер от 1 до max и возвращает его вместе с текстом после номера
+func extractNumber(text string, max int) (int, string) {
+	parts := strings.Fields(text)
+	for i, part := range parts {
+		if num, err := strconv.Atoi(part); err == nil && num > 0 && num <= max {
+			return num, strings.Join(parts[i+1:], " ")
+		}
+	}
+	return 0, ""
+}
+
+// textBeforeNumber возвращает часть команды до номера, который находит extractNumber:
+// "изменить срок задачи 1 завтра" -> "изменить срок задачи"
+func textBeforeNumber(text string, max int) string {
+	parts := strings.Fields(text)
+	for i, part := range parts {
+		if num, err := strconv.Atoi(part); err == nil && num > 0 && num <= max {
+			return strings.Join(parts[:i], " ")
+		}
+	}
+	return text
+}
+
+func (h *Handler) editTask(text, userID string) string {
+	tasks, _ := h.storage.GetUserTasks(userID)
+	if len(tasks) == 0 {
+		return "📝 У тебя пока нет задач!"
+	}
+
+	taskNumber, value := extractNumber(text, len(tasks))
+	if taskNumber == 0 {
+		return "❌ Укажи номер задачи. Например: \"изменить задачу 1 новый текст\""
+	}
+	value = strings.TrimSpace(value)
+	task := tasks[taskNumber-1]
+
+	// Поле ищем только до номера задачи: слово "срок" может быть и в новом тексте
+	command := textBeforeNumber(text, len(tasks))
+
+	var field, oldValue, newValue string
+	switch {
+	case strings.Contains(command, "срок") || strings.Contains(command, "дедлайн"):
+		field = "deadline"
+		if task.Deadline != nil {
+			oldValue = formatWhen(*task.Deadline)
+		}
+		task.ReminderSent = false
+		task.OverdueNotified = false
+		if value == "" || value == "нет" || value == "без срока" {
+			task.Deadline = nil
+			newValue = ""
+			break
+		}
+		deadline, rest, ok := parseWhen(value, h.userNow(task.UserID), endOfDay)
+		if !ok || rest != "" {
+			return "❌ Не понял срок. Например: \"изменить срок задачи 1 завтра 18:00\" или \"изменить срок задачи 1 25.12\""
+		}
+		task.Deadline = &deadline
+		newValue = formatWhen(deadline)
+	case strings.Contains(command, "приоритет"):
+		priority, ok := priorityNames[value]
+		if !ok {
+			return "❌ Приоритет может быть: высокий, средний, низкий"
+		}
+		field, oldValue, newValue = "priority", priorityTitles[task.Priority], value
+		task.Priority = priority
+	case strings.Contains(command, "оценк"):
+		estimate, ok := parseEstimateValue(value)
+		if !ok {
+			return fmt.Sprintf("❌ Оценка - число помодоро от 1 до %d. Например: \"изменить оценку задачи 1 3\"", estimateMax)
+		}
+		field, oldValue, newValue = "estimate", formatEstimate(task.Estimate), formatEstimate(estimate)
+		task.Estimate = estimate
+	case strings.Contains(command, "категори"):
+		category, ok := categoryNames[value]
+		if !ok {
+			return "❌ Категория может быть: учеба, работа, личное"
+		}
+		field, oldValue, newValue = "category", categoryTitles[task.Category], categoryTitles[category]
+		task.Category = category
+	default:
+		if value == "" {
+			return "❌ Новый текст задачи не может быть пустым"
+		}
+		field, oldValue, newValue = "text", task.Text, value
+		task.Text = value
+	}
+
+	if oldValue == newValue {
+		return "🤷 Ничего не изменилось"
+	}
+
+	task.History = append(task.History, models.TaskEdit{
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: newValue,
+		Changed:  time.Now(),
+	})
+
+	if err := h.storage.UpdateTask(task); err != nil {
+		return "❌ Ошибка при изменении задачи"
+	}
+
+	return fmt.Sprintf("✏️ Задача %d изменена\n\n%s\n\nИспользуй \"история задачи %d\" чтобы посмотреть все изменения.",
+		taskNumber, formatTaskEdit(task.History[len(task.History)-1]), taskNumber)
+}
+
+func (h *Handler) taskHistory(text, userID string) string {
+	tasks, _ := h.storage.GetUserTasks(userID)
+	taskNumber, _ := extractNumber(text, len(tasks))
+	if taskNumber == 0 {
+		return "❌ Укажи номер задачи. Например: \"история задачи 1\""
+	}
+
+	task := tasks[taskNumber-1]
+	if len(task.History) == 0 {
+		return fmt.Sprintf("📜 Задача \"%s\" еще не изменялась", task.Text)
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("📜 История задачи \"%s\":\n\n", task.Text))
+	response.WriteString(fmt.Sprintf("• %s - создана\n", task.Created.Format("02.01 15:04")))
+	for _, edit := range task.History {
+		response.WriteString(fmt.Sprintf("• %s - %s\n", edit.Changed.Format("02.01 15:04"), formatTaskEdit(edit)))
+	}
+
+	return response.String()
+}
+
+func formatTaskEdit(edit models.TaskEdit) string {
+	oldValue, newValue := edit.OldValue, edit.NewValue
+	if oldValue == "" {
+		oldValue = "нет"
+	}
+	if newValue == "" {
+		newValue = "нет"
+	}
+
+	switch edit.Field {
+	case "deadline":
+		return fmt.Sprintf("срок: %s → %s", oldValue, newValue)
+	case "priority":
+		return fmt.Sprintf("приоритет: %s → %s", oldValue, newValue)
+	case "category":
+		return fmt.Sprintf("категория: %s → %s", oldValue, newValue)
+	case "estimate":
+		return fmt.Sprintf("оценка: %s → %s", oldValue, newValue)
+	default:
+		return fmt.Sprintf("текст: \"%s\" → \"%s\"", oldValue, newValue)
+	}
+}
+
 func (h *Handler) handleTaskCallback(ctx context.Context, api *maxbot.Api, upd *schemes.MessageCallbackUpdate, userID string, chatID int64) {
 	payload := upd.Callback.Payload
 
-	if strings.HasPrefix(payload, "task_complete_") {
+	if strings.HasPrefix(payload, "task_item_") {
+		h.toggleChecklistItemByID(ctx, api, userID, chatID, strings.TrimPrefix(payload, "task_item_"))
+	} else if strings.HasPrefix(payload, "task_complete_") {
 		taskID := strings.TrimPrefix(payload, "task_complete_")
 		h.completeTaskByID(ctx, api, userID, chatID, taskID)
+	} else if strings.HasPrefix(payload, "task_tomorrow_") {
+		h.moveTasksToTomorrow(ctx, api, userID, chatID, strings.TrimPrefix(payload, "task_tomorrow_"))
 	} else if strings.HasPrefix(payload, "task_delete_") {
 		taskID := strings.TrimPrefix(payload, "task_delete_")
 		h.deleteTaskByID(ctx, api, userID, chatID, taskID)
@@ -443,10 +976,24 @@ func (h *Handler) completeTaskByID(ctx context.Context, api *maxbot.Api, userID
 	tasks, _ := h.storage.GetUserTasks(userID)
 	for _, task := range tasks {
 		if task.ID == taskID {
-			task.Completed = true
-			// Здесь нужно обновить задачу в storage
+			if task.Completed {
+				response := fmt.Sprintf("✅ Задача уже выполнена: \"%s\"", task.Text)
+				api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+				return
+			}
+			token, achieved, err := h.completeTaskWithUndo(task)
+			if err != nil {
+				api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("❌ Ошибка при обновлении задачи"))
+				return
+			}
 			response := fmt.Sprintf("✅ Задача выполнена: \"%s\"", task.Text)
-			api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+			if result := h.estimateResult(task); result != "" {
+				response += "\n" + result
+			}
+			if achieved != nil {
+				response += "\n\n" + h.goalCompletedMessage(achieved)
+			}
+			h.sendWithUndo(ctx, api, chatID, response, token)
 			return
 		}
 	}
@@ -456,13 +1003,13 @@ func (h *Handler) deleteTaskByID(ctx context.Context, api *maxbot.Api, userID st
 	tasks, _ := h.storage.GetUserTasks(userID)
 	for _, task := range tasks {
 		if task.ID == taskID {
-			err := h.storage.DeleteTask(userID, taskID)
+			token, err := h.deleteTaskWithUndo(task)
 			if err != nil {
 				api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText("❌ Ошибка при удалении задачи"))
 				return
 			}
 			response := fmt.Sprintf("✅ Задача удалена: \"%s\"", task.Text)
-			api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+			h.sendWithUndo(ctx, api, chatID, response, token)
 			return
 		}
 	}
@@ -470,14 +1017,18 @@ func (h *Handler) deleteTaskByID(ctx context.Context, api *maxbot.Api, userID st
 
 // ========== GOAL FUNCTIONALITY ==========
 
-func (h *Handler) handleGoalCommand(text, userID string) string {
+func (h *Handler) handleGoalCommand(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
 	text = strings.ToLower(text)
 
 	switch {
+	case isGoalMetricCommand(text):
+		return h.handleGoalMetricCommand(text, userID)
+	case isGoalCheckInCommand(text):
+		return h.setGoalCheckInDay(text, userID)
 	case strings.Contains(text, "добав") && strings.Contains(text, "цел"):
-		return h.addGoal(text, userID)
+		return h.addGoal(ctx, api, text, userID, chatID)
 	case strings.Contains(text, "удали") && strings.Contains(text, "цел"):
-		return h.deleteGoal(text, userID)
+		return h.deleteGoal(ctx, api, text, userID, chatID)
 	case strings.Contains(text, "прогресс") && strings.Contains(text, "цел"):
 		return h.updateGoalProgress(text, userID)
 	case strings.Contains(text, "список") && strings.Contains(text, "цел"):
@@ -487,39 +1038,93 @@ func (h *Handler) handleGoalCommand(text, userID string) string {
 	}
 }
 
-func (h *Handler) addGoal(text, userID string) string {
-	// Упрощенная логика добавления цели
-	parts := strings.Split(text, "цел")
+func (h *Handler) addGoal(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
+	// Название цели - все после слова "цель"
+	parts := strings.SplitN(text, "цел", 2)
 	if len(parts) < 2 {
 		return "❌ Укажи описание цели. Например: \"добавить цель выучить английский\""
 	}
 
-	goalTitle := strings.TrimSpace(parts[1])
+	_, goalTitle, _ := strings.Cut(parts[1], " ")
+	goalTitle = strings.TrimSpace(goalTitle)
 	if goalTitle == "" {
-		return "❌ Название цели не может быть пустым"
+		// Название не указано - спрашиваем его по шагам
+		return h.startDialog(ctx, api, "add_goal", userID, chatID)
 	}
 
+	goal := newGoal(userID, goalTitle)
+	err := h.storage.SaveGoal(goal)
+	if err != nil {
+		return "❌ Ошибка при добавлении цели"
+	}
+
+	response := fmt.Sprintf("✅ Цель добавлена: \"%s\"", goalTitle)
+	if goal.Target > 0 {
+		response += fmt.Sprintf("\n📏 Измеримая цель: %s. Отмечай продвижение: \"цель N +1 %s\"", formatGoalValue(goal), goal.Unit)
+	}
+	return response + "\n\nИспользуй \"список целей\" чтобы посмотреть все цели."
+}
+
+// newGoal создает цель со сроком по умолчанию через месяц.
+// Число с единицей в названии ("прочитать 12 книг") становится целевым значением.
+func newGoal(userID, title string) *models.Goal {
 	goal := &models.Goal{
-		ID:          fmt.Sprintf("%d", time.Now().Unix()),
+		ID:          newID(),
 		UserID:      userID,
-		Title:       goalTitle,
-		Description: "Описание цели",
+		Title:       title,
+		Description: "",
 		Created:     time.Now(),
 		Deadline:    time.Now().AddDate(0, 1, 0), // +1 месяц
 		Progress:    0,
 		Completed:   false,
 		Steps:       []models.GoalStep{},
 	}
+	// Еженедельная проверка прогресса по умолчанию - по воскресеньям
+	checkInDay := time.Sunday
+	goal.CheckInDay = &checkInDay
+	if target, unit, ok := parseGoalTarget(title); ok {
+		goal.Target = target
+		goal.Unit = unit
+	}
+	return goal
+}
 
-	err := h.storage.SaveGoal(goal)
-	if err != nil {
-		return "❌ Ошибка при добавлении цели"
+// editGoal меняет описание или срок цели: "описание цели 1 ...", "изменить срок цели 1 31.12"
+func (h *Handler) editGoal(text, userID string) string {
+	match := goalDetailPattern.FindStringSubmatch(text)
+	goal, ok := h.goalByNumber(userID, match[2])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+	value := strings.TrimSpace(match[3])
+
+	if strings.HasPrefix(match[1], "описани") {
+		if value == "" {
+			return "❌ Например: \"описание цели 1 сдать IELTS на 7.0\""
+		}
+		goal.Description = value
+		if err := h.storage.UpdateGoal(goal); err != nil {
+			return "❌ Ошибка при обновлении цели"
+		}
+		return fmt.Sprintf("✏️ Описание цели \"%s\" обновлено:\n%s", goal.Title, goal.Description)
 	}
 
-	return fmt.Sprintf("✅ Цель добавлена: \"%s\"\n\nИспользуй \"список целей\" чтобы посмотреть все цели.", goalTitle)
+	deadline, rest, ok := parseWhen(value, h.userNow(userID), endOfDay)
+	if !ok || rest != "" {
+		return "❌ Не понял срок. Например: \"изменить срок цели 1 31.12\" или \"срок цели 1 через 2 недели\""
+	}
+	if !deadline.After(goal.Created) {
+		return "❌ Срок должен быть позже даты создания цели"
+	}
+
+	goal.Deadline = deadline
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		return "❌ Ошибка при обновлении цели"
+	}
+	return fmt.Sprintf("✏️ Новый срок цели \"%s\"\n%s", goal.Title, describeGoalForecast(goal, time.Now()))
 }
 
-func (h *Handler) deleteGoal(text, userID string) string {
+func (h *Handler) deleteGoal(ctx context.Context, api *maxbot.Api, text, userID string, chatID int64) string {
 	goals, _ := h.storage.GetUserGoals(userID)
 	if len(goals) == 0 {
 		return "🎯 У тебя пока нет целей для удаления!"
@@ -540,18 +1145,81 @@ func (h *Handler) deleteGoal(text, userID string) string {
 	}
 
 	goalToDelete := goals[goalNumber-1]
-	// Здесь нужно добавить метод DeleteGoal в storage
-	return fmt.Sprintf("✅ Цель удалена: \"%s\"", goalToDelete.Title)
+	if err := h.storage.DeleteGoal(userID, goalToDelete.ID); err != nil {
+		return "❌ Ошибка при удалении цели"
+	}
+
+	token := h.pushUndo(userID, "удаление цели", func(ctx context.Context, api *maxbot.Api, chatID int64) string {
+		if err := h.storage.UndeleteGoal(userID, goalToDelete.ID); err != nil {
+			return "❌ Цель уже удалена окончательно"
+		}
+		return fmt.Sprintf("↩ Цель восстановлена: \"%s\"", goalToDelete.Title)
+	})
+	h.sendWithUndo(ctx, api, chatID, fmt.Sprintf("✅ Цель удалена: \"%s\"", goalToDelete.Title), token)
+	return ""
 }
 
+// updateGoalProgress выставляет прогресс цели вручную: "прогресс цель 1 50"
 func (h *Handler) updateGoalProgress(text, userID string) string {
 	goals, _ := h.storage.GetUserGoals(userID)
 	if len(goals) == 0 {
 		return "🎯 У тебя пока нет целей!"
 	}
 
-	// Упрощенная логика обновления прогресса
-	return "🔄 Функция обновления прогресса целей скоро будет доступна!"
+	match := goalProgressPattern.FindStringSubmatch(text)
+	if match == nil {
+		return "❌ Укажи номер цели и прогресс в процентах. Например: \"прогресс цель 1 50\""
+	}
+
+	goal, ok := h.goalByNumber(userID, match[1])
+	if !ok {
+		return "❌ Цель с таким номером не найдена"
+	}
+	if goal.Target > 0 {
+		return fmt.Sprintf("📏 Цель \"%s\" измеримая (%s). Отмечай продвижение: \"цель %s +1 %s\"", goal.Title, formatGoalValue(goal), match[1], goal.Unit)
+	}
+	if goal.ProgressFromTasks {
+		return fmt.Sprintf("🔗 Прогресс цели \"%s\" считается по привязанным задачам. Вернуть ручной учет: \"прогресс цели %s по шагам\"", goal.Title, match[1])
+	}
+	if len(goal.Steps) > 0 {
+		return fmt.Sprintf("🪜 Прогресс цели \"%s\" считается по шагам. Отмечай выполненные шаги: \"шаги цели %s\"", goal.Title, match[1])
+	}
+
+	progress, err := strconv.Atoi(match[2])
+	if err != nil || progress < 0 || progress > 100 {
+		return "❌ Прогресс должен быть от 0 до 100%"
+	}
+
+	wasCompleted := goal.Completed
+	h.setGoalProgress(goal, progress)
+	recordGoalCheckIn(goal, "")
+	if err := h.storage.UpdateGoal(goal); err != nil {
+		return "❌ Ошибка при обновлении прогресса"
+	}
+
+	if goal.Completed && !wasCompleted {
+		return h.goalCompletedMessage(goal)
+	}
+	return fmt.Sprintf("📈 Прогресс цели \"%s\": %d%%\n%s", goal.Title, goal.Progress, h.createProgressBar(goal.Progress))
+}
+
+// setGoalProgress выставляет прогресс цели; на 100% цель считается достигнутой
+// и за нее начисляется опыт
+func (h *Handler) setGoalProgress(goal *models.Goal, progress int) {
+	goal.Progress = progress
+	goal.Completed = progress >= 100
+	if !goal.Completed {
+		goal.CompletedAt = nil
+	} else if goal.CompletedAt == nil {
+		now := time.Now()
+		goal.CompletedAt = &now
+		h.emitEvent(goal.UserID, gamification.GoalCompleted, goal.ID)
+	}
+}
+
+// goalCompletedMessage поздравляет с достижением цели
+func (h *Handler) goalCompletedMessage(goal *models.Goal) string {
+	return fmt.Sprintf("🏆 Поздравляю! Цель \"%s\" достигнута - 100%%!\n\nТак держать, ставь следующую цель 🚀", goal.Title)
 }
 
 func (h *Handler) listGoals(userID string) string {
@@ -561,6 +1229,7 @@ func (h *Handler) listGoals(userID string) string {
 		return "🎯 У тебя пока нет целей!\n\nДобавь первую цель написав \"добавить цель [название]\""
 	}
 
+	now := time.Now()
 	var response strings.Builder
 	response.WriteString("🎯 Твои цели:\n\n")
 
@@ -572,7 +1241,35 @@ func (h *Handler) listGoals(userID string) string {
 			status = "🟢"
 		}
 		progressBar := h.createProgressBar(goal.Progress)
-		response.WriteString(fmt.Sprintf("%s %d. %s\n%s %d%%\n\n", status, i+1, goal.Title, progressBar, goal.Progress))
+		response.WriteString(fmt.Sprintf("%s %d. %s\n", status, i+1, goal.Title))
+		if goal.Description != "" {
+			response.WriteString(fmt.Sprintf("📄 %s\n", goal.Description))
+		}
+		response.WriteString(fmt.Sprintf("%s %d%%\n", progressBar, goal.Progress))
+		if goal.Target > 0 {
+			response.WriteString(fmt.Sprintf("📏 %s\n", formatGoalValue(goal)))
+		}
+		response.WriteString(describeGoalForecast(goal, now) + "\n")
+		if linked := h.linkedTasks(userID, goal.ID); len(linked) > 0 {
+			done, total := tasksProgress(linked)
+			source := ""
+			if goal.ProgressFromTasks {
+				source = " (прогресс по задачам)"
+			}
+			response.WriteString(fmt.Sprintf("🔗 Задачи %d/%d%s - \"задачи цели %d\"\n", done, total, source, i+1))
+		}
+		if len(goal.Steps) > 0 {
+			done, total := goalStepsProgress(goal)
+			response.WriteString(fmt.Sprintf("🪜 Шаги %d/%d:\n", done, total))
+			for j, step := range goal.Steps {
+				mark := "⬜"
+				if step.Completed {
+					mark = "✅"
+				}
+				response.WriteString(fmt.Sprintf("   %s %d. %s\n", mark, j+1, step.Text))
+			}
+		}
+		response.WriteString("\n")
 	}
 
 	return response.String()
@@ -596,7 +1293,13 @@ func (h *Handler) createProgressBar(progress int) string {
 }
 
 func (h *Handler) handleGoalCallback(ctx context.Context, api *maxbot.Api, upd *schemes.MessageCallbackUpdate, userID string, chatID int64) {
-	// Аналогично задачам - обработка callback для целей
+	payload := upd.Callback.Payload
+
+	if strings.HasPrefix(payload, "goal_checkin_") {
+		h.handleGoalCheckInCallback(ctx, api, userID, chatID, strings.TrimPrefix(payload, "goal_checkin_"))
+	} else if strings.HasPrefix(payload, "goal_step") {
+		h.handleGoalStepCallback(ctx, api, userID, chatID, strings.TrimPrefix(payload, "goal_"))
+	}
 }
 
 // ========== ОСТАВШИЕСЯ МЕТОДЫ ==========
@@ -627,26 +1330,104 @@ func (h *Handler) getMainMenu() string {
 Или просто напиши что тебя интересует! 😊`
 }
 
-func (h *Handler) getHelpMessage() string {
-	return `🆘 Помощь по командам
+func (h *Handler) getHelpMessage(userID string) string {
+	workDuration, breakDuration := h.pomodoroDurations(userID)
+	return fmt.Sprintf(`🆘 Помощь по командам
 
 🎯 Pomodoro таймер:
-• "старт помодоро" - начать сессию (25 мин)
+• "старт помодоро" - начать сессию (%d мин)
+• "старт помодоро 2" - сессия над задачей 2: засчитается в ее оценку
 • "стоп помодоро" - завершить сессию
-• "перерыв" - начать перерыв (5 мин)
+• "перерыв" - начать перерыв (%d мин)
+• "отвлёкся: [мысль]" - записать отвлечение во время сессии, после нее предложу сделать задачу
+• "настройки" - длительность сессий и напоминаний (по шагам)
+
+↩ "отменить" - отменить последнее удаление, выполнение задачи или остановку помодоро (в течение 5 минут)
 
 📝 Управление задачами:
 • "добавить задачу [описание]" - новая задача
+• "добавить задачу" - бот спросит описание, срок и приоритет по шагам
 • "список задач" - все задачи
 • "выполнить задачу 1" - отметить выполненной
 • "удалить задачу 1" - удалить задачу
+• "изменить задачу 1 [текст]" - исправить текст
+• "изменить срок задачи 1 завтра 18:00" - срок
+• "изменить приоритет задачи 1 высокий" - приоритет
+• "изменить категорию задачи 1 работа" - категория
+• "добавить задачу отчёт ~3🍅" - задача с оценкой в помодоро ("изменить оценку задачи 1 4" - изменить)
+• "история задачи 1" - история изменений
+• "добавить пункт к задаче 1 [текст]" - пункт чеклиста
+• "чеклист задачи 1" - пункты с кнопками
+• "отметить пункт 2 задачи 1" - отметить пункт
+• "удалить пункт 2 задачи 1" - удалить пункт
+• "добавить задачу [описание] каждый день" - повторяющаяся задача (также "по будням", "каждую неделю пн ср", "каждый месяц 15")
+• "повторяющиеся задачи" - все серии
+• "остановить повтор задачи 1" - остановить серию
+• "архив задач" - выполненные задачи в архиве
+• "восстановить задачу из архива 1" - вернуть задачу
+• "архивировать через 3 дня" - когда убирать выполненные в архив
+• "удалить выполненные задачи" - убрать все выполненные (с подтверждением)
+• "очистить все задачи" - удалить все задачи (с подтверждением)
+
+🌱 Привычки:
+• "добавить привычку зарядка ежедневно" - новая привычка (также "по будням", "по пн ср пт")
+• "привычки" - список с кнопками "✅ Сделал", сериями и процентом выполнения
+• "сделал привычку 1" - отметить за сегодня (повторно - снять отметку)
+• "календарь привычки 1" - календарь за месяц
+• "удалить привычку 1" - удалить привычку
+
+⏰ Напоминания:
+• "напомни через 2 часа позвонить" - разовое напоминание
+• "напомни завтра в 10:00 [текст]" - напоминание на время
+• "напоминания" - список напоминаний
+• "напоминать за 30 минут" - когда напоминать о сроках задач
+• "уведомления" - какие уведомления включены
+• "выключить уведомления о сводках" - отключить отдельный вид (помодоро, напоминания, сводки, проверки)
+• "тихие часы 23:00-08:00" - не беспокоить ночью: некритичные уведомления придут утром
+• "напоминать при неактивности через 5 дней" / "не напоминать при неактивности" - позвать, если давно не заходил
+• "выключить уведомления" - отключить все уведомления
+
+☀️ Сводки:
+• "утренняя сводка в 8:00" - каждое утро задачи на сегодня, просрочки и цели в фокусе
+• "вечерний итог в 21:00" - итоги дня с переносом невыполненного на завтра
+• "утренняя сводка" / "вечерний итог" - показать сейчас
+• "выключить утреннюю сводку" / "выключить вечерний итог"
+• "часовой пояс +3" - твой часовой пояс для сводок, сроков и напоминаний
+
+💬 В пошаговом диалоге "/cancel" или "отмена" - прервать его
+
+📊 Статистика:
+• "статистика" - общая статистика с графиками фокуса, задач и календарем активности
+• "статистика неделя" / "статистика месяц" - отчет за период со сравнением с прошлым
+• "инсайты" - лучшие часы и дни для работы, прерывания, скорость выполнения задач
+• "точность оценок" - сколько помодоро задачи занимают на самом деле по сравнению с оценкой
+• "достижения" - уровень, опыт и полученные достижения
 
 🎯 Управление целями:
 • "добавить цель [название]" - новая цель
+• "добавить цель" - бот спросит название и срок по шагам
 • "список целей" - все цели
-• "прогресс цель 1 50" - обновить прогресс
-
-Просто напиши нужную команду! 🚀`
+• "добавить шаг к цели 1 [текст]" - шаг к цели (несколько - через запятую)
+• "шаги цели 1" - шаги с кнопками
+• "выполнить шаг 2 цели 1" - отметить шаг
+• "переместить шаг 3 цели 1 на 1" - изменить порядок
+• "удалить шаг 2 цели 1" - удалить шаг
+• "описание цели 1 [текст]" - описание цели
+• "изменить срок цели 1 31.12" - срок цели (в списке целей - прогноз: в графике / есть риск / отстает)
+• "привязать задачу 1 к цели 2" - связать задачу с целью (или "к шагу 3 цели 2")
+• "отвязать задачу 1" - убрать связь с целью
+• "задачи цели 1" - задачи, привязанные к цели
+• "прогресс цели 1 по задачам" - считать прогресс по выполненным задачам ("по шагам" - вернуть)
+• "добавить цель прочитать 12 книг" - измеримая цель (12 книг - целевое значение)
+• "установить цель 1 100 км" - сделать цель измеримой
+• "цель 1 +5 км" - отметить продвижение ("цель 1 = 40" - задать значение)
+• "история цели 1" - история отметок
+• "проверка цели 1 по пятницам" - день еженедельной проверки ("отключить проверку цели 1" - выключить)
+• "напоминать о целях через 5 дней" - напомнить о цели без обновлений ("не напоминать о целях" - выключить)
+• "прогресс цель 1 50" - обновить прогресс (у целей с шагами считается по шагам, на 100%% цель достигнута)
+• "удалить все цели" - удалить все цели (с подтверждением)
+
+Просто напиши нужную команду! 🚀`, workDuration, breakDuration)
 }
 
 func (h *Handler) getTasksStatus(userID string) string {
@@ -679,12 +1460,17 @@ func (h *Handler) getGoalsStatus(userID string) string {
 
 	completed := 0
 	inProgress := 0
+	paces := map[goalPace]int{}
+	now := time.Now()
 	for _, goal := range goals {
 		if goal.Completed {
 			completed++
+			continue
 		} else if goal.Progress > 0 {
 			inProgress++
 		}
+		pace, _ := goalForecast(goal, now)
+		paces[pace]++
 	}
 
 	return fmt.Sprintf(`🎯 Работа с целями
@@ -695,17 +1481,31 @@ func (h *Handler) getGoalsStatus(userID string) string {
 • В процессе: %d
 • Новые: %d
 
+📅 Прогноз по активным целям:
+• %s: %d
+• %s: %d
+• %s: %d
+
 Команды:
 • "добавить цель [название]" - новая цель
 • "список целей" - посмотреть все цели
-• "прогресс цель 1 50" - обновить прогресс`,
-		len(goals), completed, inProgress, len(goals)-completed-inProgress)
+• "прогресс цель 1 50" - обновить прогресс
+• "описание цели 1 [текст]" - описание
+• "изменить срок цели 1 31.12" - срок`,
+		len(goals), completed, inProgress, len(goals)-completed-inProgress,
+		paceTitles[paceOnTrack], paces[paceOnTrack],
+		paceTitles[paceAtRisk], paces[paceAtRisk],
+		paceTitles[paceBehind], paces[paceBehind])
 }
 
 func (h *Handler) getStats(userID string) string {
 	stats, _ := h.storage.GetPomodoroStats(userID)
 	tasks, _ := h.storage.GetUserTasks(userID)
 	goals, _ := h.storage.GetUserGoals(userID)
+	archived, _ := h.storage.GetArchivedTasks(userID)
+
+	// Архивные задачи тоже выполнены и учитываются в статистике
+	tasks = append(append([]*models.Task{}, tasks...), archived...)
 
 	completedTasks := 0
 	for _, task := range tasks {
@@ -719,24 +1519,45 @@ func (h *Handler) getStats(userID string) string {
 		taskCompletion = float64(completedTasks) / float64(len(tasks)) * 100
 	}
 
+	habitCount, habitsDone, habitsDue, habitStreak := h.habitsSummary(userID, h.userNow(userID))
+	experience, _ := h.storage.GetExperience(userID)
+
+	distractions, workSessions := h.distractionStats(userID)
+	distractionRate := 0.0
+	if workSessions > 0 {
+		distractionRate = float64(distractions) / float64(workSessions)
+	}
+
 	return fmt.Sprintf(`📊 Статистика продуктивности
 
 🎯 Фокус:
 • Сессий Pomodoro: %d
 • Время фокуса: %d мин.
 • Текущая серия: %d дней
+• Отвлечений: %d (в среднем %.1f за сессию)
+%s
 
 📝 Задачи:
 • Всего задач: %d
 • Выполнено: %d (%.0f%%)
+• В архиве: %d
 
 🎯 Цели:
 • Активных целей: %d
 
+🌱 Привычки:
+• Всего привычек: %d
+• Сегодня выполнено: %d из %d
+• Лучшая текущая серия: %d дн.
+
+⭐ Уровень %d (%d XP), достижений: %d из %d
+
 Продолжай в том же духе! 💪`,
-		stats.TotalSessions, stats.TotalFocusTime, stats.CurrentStreak,
-		len(tasks), completedTasks, taskCompletion,
-		len(goals))
+		stats.TotalSessions, stats.TotalFocusTime, stats.CurrentStreak, distractions, distractionRate, h.interruptionSummary(userID),
+		len(tasks), completedTasks, taskCompletion, len(archived),
+		len(goals),
+		habitCount, habitsDone, habitsDue, habitStreak,
+		gamification.Level(experience.XP), experience.XP, len(experience.Achievements), len(gamification.Achievements))
 }
 
 // Callback handlers (оставшиеся)
@@ -751,9 +1572,3 @@ func (h *Handler) handleGoalsList(ctx context.Context, api *maxbot.Api, upd *sch
 	chatID := upd.Callback.GetChatID()
 	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
 }
-
-func (h *Handler) handleStats(ctx context.Context, api *maxbot.Api, upd *schemes.MessageCallbackUpdate, userID string) {
-	response := h.getStats(userID)
-	chatID := upd.Callback.GetChatID()
-	api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
-}
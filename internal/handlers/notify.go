@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// notificationKind - вид уведомления: к какой настройке он относится и можно ли отложить его до конца тихих часов
+type notificationKind struct {
+	setting  string
+	critical bool
+}
+
+var (
+	// Конец помодоро и разовые напоминания пользователь ждет в конкретное время - их не откладываем
+	notifyPomodoro = notificationKind{setting: "pomodoro", critical: true}
+	notifyReminder = notificationKind{setting: "reminders", critical: true}
+	notifyDeadline = notificationKind{setting: "reminders"}
+	notifyDigest   = notificationKind{setting: "digests"}
+	notifyCheckIn  = notificationKind{setting: "checkins"}
+	notifyNudge    = notificationKind{setting: "nudges"}
+)
+
+// notificationSettings - настраиваемые виды уведомлений в порядке показа
+var notificationSettings = []struct {
+	setting, title string
+	keywords       []string
+}{
+	{"pomodoro", "окончание помодоро и перерыва", []string{"помодоро", "таймер", "перерыв"}},
+	{"reminders", "напоминания и сроки задач", []string{"напоминани", "срок"}},
+	{"digests", "утренняя сводка и вечерний итог", []string{"сводк", "итог"}},
+	{"checkins", "проверки целей и привычек", []string{"провер", "цел", "привычк"}},
+	{"nudges", "напоминания, если давно не заходил", []string{"неактивн", "возвращ"}},
+}
+
+var notificationCommandPattern = regexp.MustCompile(`^(?:уведомления$|(?:включ|выключ|отключ)\S*\s+(?:уведомлени|тихие\s+час)|тихие\s+час)`)
+
+var quietHoursPattern = regexp.MustCompile(`^тихие\s+час\S*\s+(?:с\s+)?(\d{1,2}:\d{2})\s*(?:-|до)\s*(\d{1,2}:\d{2})$`)
+
+// queuedNotification - уведомление, отложенное до конца тихих часов
+type queuedNotification struct {
+	text     string
+	keyboard *maxbot.Keyboard
+}
+
+// notify отправляет пользователю сообщение по инициативе бота (напоминания и т.п.).
+// Учитывает общую настройку и настройку вида уведомлений; в тихие часы некритичные
+// уведомления откладываются (см. deliverQueuedNotifications).
+// Возвращает true, если сообщение отправлено или отложено.
+func (h *Handler) notify(ctx context.Context, api *maxbot.Api, userID string, kind notificationKind, text string, keyboard *maxbot.Keyboard) bool {
+	data, _ := h.storage.GetUserData(userID)
+	if data != nil {
+		if !data.Settings.NotificationsEnabled || data.Settings.DisabledNotifications[kind.setting] {
+			return false
+		}
+		if !kind.critical && inQuietHours(data.Settings, time.Now().In(h.userLocation(userID))) {
+			h.quietQueue[userID] = append(h.quietQueue[userID], queuedNotification{text: text, keyboard: keyboard})
+			return true
+		}
+	}
+	return h.deliver(ctx, api, userID, text, keyboard)
+}
+
+// deliverQueuedNotifications отправляет уведомления, накопившиеся за тихие часы.
+// Неотправленные остаются в очереди до следующего тика.
+func (h *Handler) deliverQueuedNotifications(ctx context.Context, api *maxbot.Api, now time.Time) {
+	for userID, queue := range h.quietQueue {
+		data, _ := h.storage.GetUserData(userID)
+		if data != nil && inQuietHours(data.Settings, now.In(h.userLocation(userID))) {
+			continue
+		}
+
+		var failed []queuedNotification
+		for _, notification := range queue {
+			if !h.deliver(ctx, api, userID, notification.text, notification.keyboard) {
+				failed = append(failed, notification)
+			}
+		}
+		if len(failed) == 0 {
+			delete(h.quietQueue, userID)
+		} else {
+			h.quietQueue[userID] = failed
+		}
+	}
+}
+
+// deliver отправляет сообщение в диалог пользователя с ботом
+func (h *Handler) deliver(ctx context.Context, api *maxbot.Api, userID, text string, keyboard *maxbot.Keyboard) bool {
+	user, _ := h.storage.GetUser(userID)
+	if user == nil {
+		return false
+	}
+
+	message := maxbot.NewMessage().SetText(text)
+	if user.ChatID != 0 {
+		message.SetChat(user.ChatID)
+	} else {
+		id, err := strconv.ParseInt(userID, 10, 64)
+		if err != nil {
+			return false
+		}
+		message.SetUser(id)
+	}
+	if keyboard != nil {
+		message.AddKeyboard(keyboard)
+	}
+
+	if _, err := api.Messages.Send(ctx, message); sendError(err) != nil {
+		fmt.Printf("❌ Error sending notification to %s: %v\n", userID, err)
+		return false
+	}
+	return true
+}
+
+// inQuietHours - попадает ли местное время пользователя в тихие часы (интервал может переходить через полночь)
+func inQuietHours(settings models.UserSettings, local time.Time) bool {
+	start, okStart := parseClock(settings.QuietHoursStart)
+	end, okEnd := parseClock(settings.QuietHoursEnd)
+	if !okStart || !okEnd || start == end {
+		return false
+	}
+
+	clock := local.Sub(startOfDay(local))
+	if start < end {
+		return clock >= start && clock < end
+	}
+	return clock >= start || clock < end
+}
+
+// isNotificationCommand - команды уведомлений: "уведомления", "выключить уведомления о сводках",
+// "тихие часы 23:00-08:00". Слово "уведомление" внутри текста задачи командой не считается.
+func isNotificationCommand(text string) bool {
+	return notificationCommandPattern.MatchString(text)
+}
+
+func (h *Handler) handleNotificationCommand(text, userID string) string {
+	if strings.Contains(text, "тихие час") {
+		return h.setQuietHours(text, userID)
+	}
+	return h.toggleNotifications(text, userID)
+}
+
+// toggleNotifications включает и выключает уведомления - все сразу или отдельного вида
+func (h *Handler) toggleNotifications(text, userID string) string {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+
+	switch {
+	case strings.HasPrefix(text, "выключ") || strings.HasPrefix(text, "отключ"):
+		if response, ok := h.toggleNotificationKind(text, data, false); ok {
+			return response
+		}
+		data.Settings.NotificationsEnabled = false
+	case strings.HasPrefix(text, "включ"):
+		if response, ok := h.toggleNotificationKind(text, data, true); ok {
+			return response
+		}
+		data.Settings.NotificationsEnabled = true
+	default:
+		return h.notificationStatus(userID)
+	}
+
+	if err := h.storage.SaveUserData(data); err != nil {
+		return "❌ Ошибка при сохранении настроек"
+	}
+	if data.Settings.NotificationsEnabled {
+		return "🔔 Уведомления включены"
+	}
+	return "🔕 Уведомления выключены. Напоминания и сообщения о сроках приходить не будут."
+}
+
+// toggleNotificationKind включает или выключает отдельный вид уведомлений: "выключить уведомления о сводках".
+// Возвращает false, если в тексте не указан вид уведомлений.
+func (h *Handler) toggleNotificationKind(text string, data *models.UserData, enable bool) (string, bool) {
+	for _, option := range notificationSettings {
+		for _, keyword := range option.keywords {
+			if !strings.Contains(text, keyword) {
+				continue
+			}
+
+			if data.Settings.DisabledNotifications == nil {
+				data.Settings.DisabledNotifications = make(map[string]bool)
+			}
+			if enable {
+				delete(data.Settings.DisabledNotifications, option.setting)
+			} else {
+				data.Settings.DisabledNotifications[option.setting] = true
+			}
+			if err := h.storage.SaveUserData(data); err != nil {
+				return "❌ Ошибка при сохранении настроек", true
+			}
+
+			if enable {
+				return fmt.Sprintf("🔔 Уведомления включены: %s", option.title), true
+			}
+			return fmt.Sprintf("🔕 Уведомления выключены: %s", option.title), true
+		}
+	}
+	return "", false
+}
+
+// setQuietHours настраивает тихие часы: "тихие часы 23:00-08:00", "выключить тихие часы"
+func (h *Handler) setQuietHours(text, userID string) string {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+
+	switch {
+	case strings.HasPrefix(text, "выключ") || strings.HasPrefix(text, "отключ"):
+		data.Settings.QuietHoursStart, data.Settings.QuietHoursEnd = "", ""
+	case quietHoursPattern.MatchString(text):
+		match := quietHoursPattern.FindStringSubmatch(text)
+		start, okStart := parseClock(match[1])
+		end, okEnd := parseClock(match[2])
+		if !okStart || !okEnd || start == end {
+			return "❌ Не понял время. Например: \"тихие часы 23:00-08:00\""
+		}
+		data.Settings.QuietHoursStart, data.Settings.QuietHoursEnd = formatClock(start), formatClock(end)
+	default:
+		return h.notificationStatus(userID)
+	}
+
+	if err := h.storage.SaveUserData(data); err != nil {
+		return "❌ Ошибка при сохранении настроек"
+	}
+	if data.Settings.QuietHoursStart == "" {
+		return "🔔 Тихие часы выключены"
+	}
+	return fmt.Sprintf("🌙 Тихие часы: %s-%s. Сводки, проверки и напоминания о сроках в это время придут позже, а конец помодоро и разовые напоминания - сразу.",
+		data.Settings.QuietHoursStart, data.Settings.QuietHoursEnd)
+}
+
+// notificationStatus - текущие настройки уведомлений
+func (h *Handler) notificationStatus(userID string) string {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+
+	var response strings.Builder
+	if data.Settings.NotificationsEnabled {
+		response.WriteString("🔔 Уведомления включены\n\n")
+	} else {
+		response.WriteString("🔕 Уведомления выключены - бот не пишет первым\n\n")
+	}
+	for _, option := range notificationSettings {
+		mark := "✅"
+		if data.Settings.DisabledNotifications[option.setting] {
+			mark = "❌"
+		}
+		response.WriteString(fmt.Sprintf("%s %s\n", mark, option.title))
+	}
+
+	if data.Settings.QuietHoursStart != "" {
+		response.WriteString(fmt.Sprintf("\n🌙 Тихие часы: %s-%s (%s)\n", data.Settings.QuietHoursStart, data.Settings.QuietHoursEnd, describeUTCOffset(h.userNow(userID))))
+	} else {
+		response.WriteString("\n🌙 Тихие часы не заданы\n")
+	}
+
+	response.WriteString(`
+Команды:
+• "выключить уведомления о сводках" / "включить уведомления о сводках"
+  (помодоро, напоминаниях, сводках, проверках, неактивности)
+• "тихие часы 23:00-08:00" / "выключить тихие часы"
+• "выключить уведомления" / "включить уведомления" - все сразу`)
+	return response.String()
+}
+
+func formatClock(clock time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(clock.Hours()), int(clock.Minutes())%60)
+}
+
+// sendError убирает "ошибку", которую клиент MAX возвращает и при успешной отправке
+// (*schemes.Error с пустым кодом)
+func sendError(err error) error {
+	var apiErr *schemes.Error
+	if errors.As(err, &apiErr) && apiErr.Code == "" {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== RE-ENGAGEMENT NUDGES ==========
+
+const (
+	// nudgeMaxCount - сколько раз подряд звать вернуться, пока пользователь не зайдет
+	nudgeMaxCount = 3
+	// Зовем только днем по местному времени пользователя
+	nudgeFromHour = 12
+	nudgeToHour   = 21
+)
+
+// setInactivityDays настраивает напоминание при неактивности: "напоминать при неактивности через 5 дней"
+func (h *Handler) setInactivityDays(text, userID string) string {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+
+	days := -1
+	if strings.Contains(text, "не ") || strings.Contains(text, "никогда") {
+		days = 0
+	} else {
+		for _, field := range strings.Fields(text) {
+			if n, err := strconv.Atoi(field); err == nil && n > 0 {
+				days = n
+				break
+			}
+		}
+	}
+	if days < 0 {
+		if data.Settings.InactivityDays == 0 {
+			return "🔕 Не напоминаю о себе, если ты долго не заходишь. Включить: \"напоминать при неактивности через 3 дня\""
+		}
+		return fmt.Sprintf("👋 Напомню о себе, если не будешь заходить %d дн. Изменить: \"напоминать при неактивности через 5 дней\" или \"не напоминать при неактивности\"", data.Settings.InactivityDays)
+	}
+
+	data.Settings.InactivityDays = days
+	if err := h.storage.SaveUserData(data); err != nil {
+		return "❌ Ошибка при сохранении настроек"
+	}
+	if days == 0 {
+		return "🔕 Больше не буду напоминать о себе"
+	}
+	return fmt.Sprintf("✅ Напомню о себе, если не будешь заходить %d дн.", days)
+}
+
+// processInactivityNudges зовет вернуться тех, кто давно не заходил.
+// Повторные напоминания - с удвоением паузы и не больше nudgeMaxCount раз до следующего визита.
+func (h *Handler) processInactivityNudges(ctx context.Context, api *maxbot.Api, now time.Time) {
+	users, _ := h.storage.GetUsers()
+	for _, user := range users {
+		userID := user.MAXUserID
+		data, _ := h.storage.GetUserData(userID)
+		if data == nil || data.Settings.InactivityDays <= 0 {
+			continue
+		}
+
+		local := now.In(h.userLocation(userID))
+		if local.Hour() < nudgeFromHour || local.Hour() >= nudgeToHour {
+			continue
+		}
+
+		if data.NudgedAt != nil && user.LastActivity.After(*data.NudgedAt) {
+			// Пользователь возвращался - начинаем отсчет заново
+			data.NudgedAt = nil
+			data.NudgeCount = 0
+		}
+		if !nudgeDue(user, data, now) {
+			continue
+		}
+
+		if h.notify(ctx, api, userID, notifyNudge, h.nudgeMessage(userID, now), h.nudgeKeyboard(api)) {
+			data.NudgedAt = &now
+			data.NudgeCount++
+			h.storage.SaveUserData(data)
+		}
+	}
+}
+
+// nudgeDue - пора ли позвать пользователя: первый раз через InactivityDays дней, затем через 2x, 4x...
+func nudgeDue(user *models.User, data *models.UserData, now time.Time) bool {
+	interval := time.Duration(data.Settings.InactivityDays) * 24 * time.Hour
+	if data.NudgedAt == nil {
+		return now.Sub(user.LastActivity) >= interval
+	}
+	if data.NudgeCount >= nudgeMaxCount {
+		return false
+	}
+	return now.Sub(*data.NudgedAt) >= interval<<data.NudgeCount
+}
+
+// nudgeMessage - мягкое напоминание с количеством открытых задач
+func (h *Handler) nudgeMessage(userID string, now time.Time) string {
+	open, overdue := 0, 0
+	tasks, _ := h.storage.GetUserTasks(userID)
+	for _, task := range tasks {
+		if task.Completed {
+			continue
+		}
+		open++
+		if task.Deadline != nil && task.Deadline.Before(now) {
+			overdue++
+		}
+	}
+
+	response := "👋 Давно не виделись! "
+	switch {
+	case open == 0:
+		response += "Открытых задач нет - самое время поставить новую цель или провести одну помодоро-сессию 🍅"
+	case overdue > 0:
+		workDuration, _ := h.pomodoroDurations(userID)
+		response += fmt.Sprintf("У тебя %d открытых задач, из них просрочено %d. Начнем с одной - это займет всего %d минут 🍅", open, overdue, workDuration)
+	default:
+		response += fmt.Sprintf("Тебя ждут %d открытых задач. Начнем с одной? 🍅", open)
+	}
+	return response + "\n\nНе напоминать: \"не напоминать при неактивности\""
+}
+
+func (h *Handler) nudgeKeyboard(api *maxbot.Api) *maxbot.Keyboard {
+	keyboard := api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback("📝 Мои задачи", schemes.POSITIVE, "tasks_list").
+		AddCallback("🔕 Не напоминать", schemes.DEFAULT, "nudge_off")
+	return keyboard
+}
@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+)
+
+// ========== RECURRING TASKS FUNCTIONALITY ==========
+
+var weekdayShortNames = []string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"}
+
+// weekdayPluralNames - формы "по понедельникам"
+var weekdayPluralNames = map[string]time.Weekday{
+	"понедельникам": time.Monday,
+	"вторникам":     time.Tuesday,
+	"средам":        time.Wednesday,
+	"четвергам":     time.Thursday,
+	"пятницам":      time.Friday,
+	"субботам":      time.Saturday,
+	"воскресеньям":  time.Sunday,
+}
+
+// parseRecurrence ищет в описании задачи правило повторения ("каждый день",
+// "по будням", "каждую неделю пн ср", "каждый месяц 15") и необязательное время
+// после него. Возвращает правило, время суток и описание без правила.
+func parseRecurrence(text string, now time.Time) (*models.Recurrence, time.Duration, string) {
+	fields := strings.Fields(text)
+	word := func(i int) string {
+		if i < len(fields) {
+			return strings.Trim(fields[i], ",")
+		}
+		return ""
+	}
+
+	for start := range fields {
+		var rule *models.Recurrence
+		i := start
+
+		switch {
+		case word(i) == "ежедневно":
+			rule, i = &models.Recurrence{Frequency: "daily"}, i+1
+		case strings.HasPrefix(word(i), "кажд") && word(i+1) == "день":
+			rule, i = &models.Recurrence{Frequency: "daily"}, i+2
+		case word(i) == "по" && word(i+1) == "будням":
+			rule, i = &models.Recurrence{Frequency: "weekdays"}, i+2
+		case word(i) == "еженедельно" || (strings.HasPrefix(word(i), "кажд") && word(i+1) == "неделю"):
+			rule = &models.Recurrence{Frequency: "weekly"}
+			if word(i) == "еженедельно" {
+				i++
+			} else {
+				i += 2
+			}
+			if word(i) == "по" || word(i) == "в" || word(i) == "во" {
+				i++
+			}
+			i = collectWeekdays(rule, fields, i)
+		case strings.HasPrefix(word(i), "кажд") && isWeekdayName(word(i+1)):
+			rule = &models.Recurrence{Frequency: "weekly"}
+			i = collectWeekdays(rule, fields, i+1)
+		case word(i) == "по" && isWeekdayName(word(i+1)):
+			rule = &models.Recurrence{Frequency: "weekly"}
+			i = collectWeekdays(rule, fields, i+1)
+		case word(i) == "ежемесячно" || (strings.HasPrefix(word(i), "кажд") && word(i+1) == "месяц"):
+			rule = &models.Recurrence{Frequency: "monthly", DayOfMonth: now.Day()}
+			if word(i) == "ежемесячно" {
+				i++
+			} else {
+				i += 2
+			}
+			if day, ok := parseDayOfMonth(word(i)); ok {
+				rule.DayOfMonth = day
+				i++
+				if strings.HasPrefix(word(i), "числ") {
+					i++
+				}
+			}
+		}
+		if rule == nil {
+			continue
+		}
+
+		if rule.Frequency == "weekly" && len(rule.Weekdays) == 0 {
+			rule.Weekdays = []time.Weekday{now.Weekday()}
+		}
+
+		clock := endOfDay
+		if word(i) == "в" {
+			if c, ok := parseClock(word(i + 1)); ok {
+				clock, i = c, i+2
+			}
+		} else if c, ok := parseClock(word(i)); ok {
+			clock, i = c, i+1
+		}
+
+		rest := append(append([]string{}, fields[:start]...), fields[i:]...)
+		return rule, clock, strings.Join(rest, " ")
+	}
+
+	return nil, 0, text
+}
+
+func isWeekdayName(s string) bool {
+	_, single := weekdayNames[s]
+	_, plural := weekdayPluralNames[s]
+	return single || plural
+}
+
+// collectWeekdays собирает перечисление дней недели ("пн, ср и пт") начиная с позиции i
+func collectWeekdays(rule *models.Recurrence, fields []string, i int) int {
+	for ; i < len(fields); i++ {
+		token := strings.Trim(fields[i], ",")
+		if token == "и" || token == "" {
+			continue
+		}
+		wd, ok := weekdayNames[token]
+		if !ok {
+			wd, ok = weekdayPluralNames[token]
+		}
+		if !ok {
+			break
+		}
+		rule.Weekdays = append(rule.Weekdays, wd)
+	}
+	sort.Slice(rule.Weekdays, func(a, b int) bool {
+		return (rule.Weekdays[a]+6)%7 < (rule.Weekdays[b]+6)%7
+	})
+	return i
+}
+
+func parseDayOfMonth(s string) (int, bool) {
+	var day int
+	if _, err := fmt.Sscanf(s, "%d", &day); err != nil || day < 1 || day > 31 {
+		return 0, false
+	}
+	return day, true
+}
+
+// occursOn проверяет, выпадает ли повторение на указанный день
+func occursOn(rule *models.Recurrence, day time.Time) bool {
+	switch rule.Frequency {
+	case "daily":
+		return true
+	case "weekdays":
+		return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+	case "weekly":
+		for _, wd := range rule.Weekdays {
+			if day.Weekday() == wd {
+				return true
+			}
+		}
+		return false
+	case "monthly":
+		// В коротких месяцах повторение переносится на последний день
+		lastDay := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		target := rule.DayOfMonth
+		if target > lastDay {
+			target = lastDay
+		}
+		return day.Day() == target
+	}
+	return false
+}
+
+// nextOccurrence возвращает следующий после after момент повторения с тем же временем суток
+func nextOccurrence(rule *models.Recurrence, after time.Time) time.Time {
+	next := after.AddDate(0, 0, 1)
+	for i := 0; i < 366 && !occursOn(rule, next); i++ {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// firstOccurrence возвращает ближайший момент повторения не раньше now
+func firstOccurrence(rule *models.Recurrence, now time.Time, clock time.Duration) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	first := today.Add(clock)
+	if !first.After(now) || !occursOn(rule, first) {
+		first = nextOccurrence(rule, first)
+	}
+	return first
+}
+
+func describeRecurrence(rule *models.Recurrence) string {
+	switch rule.Frequency {
+	case "daily":
+		return "каждый день"
+	case "weekdays":
+		return "по будням"
+	case "weekly":
+		days := make([]string, 0, len(rule.Weekdays))
+		for _, wd := range rule.Weekdays {
+			days = append(days, weekdayShortNames[wd])
+		}
+		return "каждую неделю: " + strings.Join(days, ", ")
+	case "monthly":
+		return fmt.Sprintf("каждый месяц, %d числа", rule.DayOfMonth)
+	}
+	return rule.Frequency
+}
+
+// seriesLatest возвращает последние (по сроку) экземпляры всех активных серий пользователя
+func seriesLatest(tasks []*models.Task) map[string]*models.Task {
+	latest := make(map[string]*models.Task)
+	for _, task := range tasks {
+		if task.Recurrence == nil || task.SeriesID == "" || task.Deadline == nil {
+			continue
+		}
+		if current, ok := latest[task.SeriesID]; !ok || task.Deadline.After(*current.Deadline) {
+			latest[task.SeriesID] = task
+		}
+	}
+	return latest
+}
+
+// ensureNextOccurrence создает следующий экземпляр серии, когда в ней не осталось
+// невыполненных задач: пока экземпляр не выполнен (даже просроченный), новые не копятся.
+// Срок считается от последнего созданного экземпляра, даже если его уже удалили
+// или убрали в архив (см. seriesCursor). Возвращает созданную задачу или nil.
+func (h *Handler) ensureNextOccurrence(latest *models.Task, tasks []*models.Task, now time.Time) *models.Task {
+	for _, task := range tasks {
+		if task.SeriesID == latest.SeriesID && !task.Completed {
+			return nil
+		}
+	}
+
+	cursor := h.seriesCursor(latest.UserID, latest.SeriesID)
+	if latest.Deadline.After(cursor) {
+		cursor = *latest.Deadline
+	}
+	deadline := nextOccurrence(latest.Recurrence, cursor)
+	for !deadline.After(now) {
+		deadline = nextOccurrence(latest.Recurrence, deadline)
+	}
+
+	rule := *latest.Recurrence
+	next := &models.Task{
+		ID:         newID(),
+		UserID:     latest.UserID,
+		Text:       latest.Text,
+		Created:    now,
+		Deadline:   &deadline,
+		Priority:   latest.Priority,
+		Category:   latest.Category,
+		Estimate:   latest.Estimate,
+		GoalID:     latest.GoalID,
+		GoalStepID: latest.GoalStepID,
+		SeriesID:   latest.SeriesID,
+		Recurrence: &rule,
+	}
+	for _, item := range latest.Items {
+		next.Items = append(next.Items, models.ChecklistItem{ID: newID(), Text: item.Text})
+	}
+
+	if err := h.storage.SaveTask(next); err != nil {
+		fmt.Printf("❌ Error creating recurring task: %v\n", err)
+		return nil
+	}
+	h.setSeriesCursor(latest.UserID, latest.SeriesID, deadline)
+	return next
+}
+
+// seriesCursor - срок последнего созданного экземпляра серии. Хранится отдельно от задач,
+// чтобы удаление или архивирование экземпляров не возвращало серию к старым срокам.
+func (h *Handler) seriesCursor(userID, seriesID string) time.Time {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return time.Time{}
+	}
+	return data.SeriesCursors[seriesID]
+}
+
+func (h *Handler) setSeriesCursor(userID, seriesID string, cursor time.Time) {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return
+	}
+	if data.SeriesCursors == nil {
+		data.SeriesCursors = make(map[string]time.Time)
+	}
+	data.SeriesCursors[seriesID] = cursor
+	h.storage.SaveUserData(data)
+}
+
+// generateRecurringTasks создает очередные экземпляры повторяющихся задач всех пользователей
+func (h *Handler) generateRecurringTasks(now time.Time) {
+	users, _ := h.storage.GetUsers()
+	for _, user := range users {
+		tasks, _ := h.storage.GetUserTasks(user.MAXUserID)
+		for _, latest := range seriesLatest(tasks) {
+			h.ensureNextOccurrence(latest, tasks, now)
+		}
+	}
+}
+
+func (h *Handler) listRecurringTasks(userID string) string {
+	tasks, _ := h.storage.GetUserTasks(userID)
+	latest := seriesLatest(tasks)
+	if len(latest) == 0 {
+		return "🔁 У тебя нет повторяющихся задач.\n\nДобавь: \"добавить задачу стендап каждый день в 10:00\""
+	}
+
+	// Номер задачи в общем списке для каждой серии - ближайший невыполненный экземпляр
+	numbers := make(map[string]int)
+	for i, task := range tasks {
+		if task.Recurrence != nil && !task.Completed {
+			if _, ok := numbers[task.SeriesID]; !ok {
+				numbers[task.SeriesID] = i + 1
+			}
+		}
+	}
+
+	series := make([]*models.Task, 0, len(latest))
+	for _, task := range latest {
+		series = append(series, task)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Deadline.Before(*series[j].Deadline)
+	})
+
+	var response strings.Builder
+	response.WriteString("🔁 Повторяющиеся задачи:\n\n")
+	for _, task := range series {
+		response.WriteString(fmt.Sprintf("• %s - %s\n", task.Text, describeRecurrence(task.Recurrence)))
+		if number, ok := numbers[task.SeriesID]; ok {
+			response.WriteString(fmt.Sprintf("  ближайшая: задача %d, ⏰ %s\n", number, formatWhen(*task.Deadline)))
+		}
+	}
+	response.WriteString("\nЧтобы остановить серию: \"остановить повтор задачи [номер]\"")
+
+	return response.String()
+}
+
+func (h *Handler) stopRecurringTask(text, userID string) string {
+	tasks, _ := h.storage.GetUserTasks(userID)
+	taskNumber, _ := extractNumber(text, len(tasks))
+	if taskNumber == 0 {
+		return "❌ Укажи номер задачи. Например: \"остановить повтор задачи 1\""
+	}
+
+	seriesID := tasks[taskNumber-1].SeriesID
+	if seriesID == "" || tasks[taskNumber-1].Recurrence == nil {
+		return "❌ Эта задача не повторяется"
+	}
+
+	for _, task := range tasks {
+		if task.SeriesID == seriesID {
+			task.Recurrence = nil
+			if err := h.storage.UpdateTask(task); err != nil {
+				return "❌ Ошибка при остановке серии"
+			}
+		}
+	}
+
+	return fmt.Sprintf("⏹ Серия \"%s\" остановлена. Уже созданные задачи остались в списке.", tasks[taskNumber-1].Text)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"proddy-bot/internal/models"
+	"proddy-bot/internal/storage"
+)
+
+// testNow - среда, 15 мая 2024, 18:00
+var testNow = time.Date(2024, time.May, 15, 18, 0, 0, 0, time.UTC)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  *models.Recurrence
+		clock time.Duration
+		rest  string
+	}{
+		{"стендап каждый день в 10:00", &models.Recurrence{Frequency: "daily"}, 10 * time.Hour, "стендап"},
+		{"зарядка ежедневно 7:30", &models.Recurrence{Frequency: "daily"}, 7*time.Hour + 30*time.Minute, "зарядка"},
+		{"отчет по будням", &models.Recurrence{Frequency: "weekdays"}, endOfDay, "отчет"},
+		{"спорт каждую неделю пн, ср", &models.Recurrence{Frequency: "weekly", Weekdays: []time.Weekday{time.Monday, time.Wednesday}}, endOfDay, "спорт"},
+		{"йога по четвергам и вторникам 19:00", &models.Recurrence{Frequency: "weekly", Weekdays: []time.Weekday{time.Tuesday, time.Thursday}}, 19 * time.Hour, "йога"},
+		{"уборка еженедельно", &models.Recurrence{Frequency: "weekly", Weekdays: []time.Weekday{time.Wednesday}}, endOfDay, "уборка"},
+		{"оплатить счета каждый месяц 31 числа", &models.Recurrence{Frequency: "monthly", DayOfMonth: 31}, endOfDay, "оплатить счета"},
+		{"полить цветы ежемесячно", &models.Recurrence{Frequency: "monthly", DayOfMonth: 15}, endOfDay, "полить цветы"},
+		{"купить хлеб", nil, 0, "купить хлеб"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			rule, clock, rest := parseRecurrence(tt.text, testNow)
+			if !reflect.DeepEqual(rule, tt.want) || clock != tt.clock || rest != tt.rest {
+				t.Errorf("parseRecurrence() = %+v, %v, %q, want %+v, %v, %q", rule, clock, rest, tt.want, tt.clock, tt.rest)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	at := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 10, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		rule  *models.Recurrence
+		after time.Time
+		want  time.Time
+	}{
+		{"daily", &models.Recurrence{Frequency: "daily"}, at(time.May, 15), at(time.May, 16)},
+		{"weekdays skip the weekend", &models.Recurrence{Frequency: "weekdays"}, at(time.May, 17), at(time.May, 20)},
+		{"weekly", &models.Recurrence{Frequency: "weekly", Weekdays: []time.Weekday{time.Monday, time.Wednesday}}, at(time.May, 15), at(time.May, 20)},
+		{"monthly", &models.Recurrence{Frequency: "monthly", DayOfMonth: 15}, at(time.May, 15), at(time.June, 15)},
+		{"monthly moves to the last day of a short month", &models.Recurrence{Frequency: "monthly", DayOfMonth: 31}, at(time.May, 31), at(time.June, 30)},
+		{"monthly returns to its day after a short month", &models.Recurrence{Frequency: "monthly", DayOfMonth: 31}, at(time.June, 30), at(time.July, 31)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextOccurrence(tt.rule, tt.after); !got.Equal(tt.want) {
+				t.Errorf("nextOccurrence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureNextOccurrence(t *testing.T) {
+	h := New(storage.NewMemoryStorage())
+	h.storage.SaveUser(&models.User{MAXUserID: "u1"})
+
+	overdue := time.Date(2024, time.May, 14, 10, 0, 0, 0, time.UTC)
+	task := &models.Task{ID: "t1", UserID: "u1", Text: "стендап", Deadline: &overdue, SeriesID: "s1", Recurrence: &models.Recurrence{Frequency: "daily"}}
+	h.storage.SaveTask(task)
+
+	if next := h.ensureNextOccurrence(task, []*models.Task{task}, testNow); next != nil {
+		t.Fatalf("open overdue instance: created %+v, want nothing", next)
+	}
+
+	task.Completed = true
+	next := h.ensureNextOccurrence(task, []*models.Task{task}, testNow)
+	if want := time.Date(2024, time.May, 16, 10, 0, 0, 0, time.UTC); next == nil || !next.Deadline.Equal(want) {
+		t.Fatalf("completed instance: created %+v, want deadline %v", next, want)
+	}
+
+	// Удаленный последний экземпляр не возвращает серию к сроку выполненного
+	h.storage.DeleteTask("u1", next.ID)
+	next = h.ensureNextOccurrence(task, []*models.Task{task}, testNow)
+	if want := time.Date(2024, time.May, 17, 10, 0, 0, 0, time.UTC); next == nil || !next.Deadline.Equal(want) {
+		t.Errorf("after deleting the latest instance: created %+v, want deadline %v", next, want)
+	}
+}
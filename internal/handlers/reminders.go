@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== REMINDERS FUNCTIONALITY ==========
+
+// isReminderCommand - команды напоминаний. Слово "напоминание" внутри текста задачи
+// или цели командой не считается.
+func isReminderCommand(text string) bool {
+	return strings.HasPrefix(text, "напомни ") || text == "напоминания" ||
+		strings.HasPrefix(text, "удалить напоминание") || strings.HasPrefix(text, "напоминать за")
+}
+
+func (h *Handler) handleReminderCommand(text, userID string) string {
+	switch {
+	case strings.HasPrefix(text, "напомни "):
+		return h.addReminder(text, userID)
+	case strings.HasPrefix(text, "напоминать за"):
+		return h.setReminderLeadTime(text, userID)
+	case strings.HasPrefix(text, "удали"):
+		return h.deleteReminder(text, userID)
+	default:
+		return h.listReminders(userID)
+	}
+}
+
+// addReminder создает разовое напоминание: "напомни через 2 часа позвонить",
+// "напомни завтра в 10:00 отправить отчет"
+func (h *Handler) addReminder(text, userID string) string {
+	request := strings.TrimSpace(strings.TrimPrefix(text, "напомни "))
+	request = strings.TrimPrefix(request, "мне ")
+
+	remindAt, rest, ok := parseWhen(request, h.userNow(userID), 9*time.Hour)
+	if !ok {
+		return "❌ Не понял когда напомнить. Например: \"напомни через 2 часа позвонить\" или \"напомни завтра в 10:00 отправить отчет\""
+	}
+	if !remindAt.After(time.Now()) {
+		return "❌ Это время уже прошло"
+	}
+
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return "❌ Укажи о чем напомнить. Например: \"напомни через 2 часа позвонить\""
+	}
+
+	reminder := &models.Reminder{
+		ID:       newID(),
+		UserID:   userID,
+		Text:     rest,
+		RemindAt: remindAt,
+	}
+	if err := h.storage.SaveReminder(reminder); err != nil {
+		return "❌ Ошибка при создании напоминания"
+	}
+
+	response := fmt.Sprintf("⏰ Напомню %s: \"%s\"", formatWhen(remindAt), rest)
+	if data, _ := h.storage.GetUserData(userID); data != nil && !data.Settings.NotificationsEnabled {
+		response += "\n\n⚠️ Уведомления выключены - напиши \"включить уведомления\", иначе напоминание не придет."
+	}
+	return response
+}
+
+func (h *Handler) listReminders(userID string) string {
+	data, _ := h.storage.GetUserData(userID)
+	reminders := h.pendingReminders(userID)
+
+	var response strings.Builder
+	response.WriteString("⏰ Напоминания\n\n")
+	if len(reminders) == 0 {
+		response.WriteString("Запланированных напоминаний нет.\n")
+	}
+	for i, reminder := range reminders {
+		response.WriteString(fmt.Sprintf("%d. %s - %s\n", i+1, formatWhen(reminder.RemindAt), reminder.Text))
+	}
+
+	if data != nil {
+		status := "включены"
+		if !data.Settings.NotificationsEnabled {
+			status = "выключены"
+		}
+		response.WriteString(fmt.Sprintf("\n🔔 Уведомления: %s\n⏳ О сроках задач напоминаю за %s", status, formatMinutes(data.Settings.ReminderLeadTime)))
+	}
+
+	response.WriteString(`
+
+Команды:
+• "напомни через 2 часа позвонить" - разовое напоминание
+• "удалить напоминание 1" - удалить напоминание
+• "напоминать за 30 минут" - когда напоминать о сроке задачи
+• "уведомления" - настройки уведомлений и тихие часы`)
+
+	return response.String()
+}
+
+func (h *Handler) deleteReminder(text, userID string) string {
+	reminders := h.pendingReminders(userID)
+	number, _ := extractNumber(text, len(reminders))
+	if number == 0 {
+		return "❌ Укажи номер напоминания. Например: \"удалить напоминание 1\""
+	}
+
+	reminder := reminders[number-1]
+	if err := h.storage.DeleteReminder(userID, reminder.ID); err != nil {
+		return "❌ Ошибка при удалении напоминания"
+	}
+	return fmt.Sprintf("🗑 Напоминание удалено: \"%s\"", reminder.Text)
+}
+
+func (h *Handler) setReminderLeadTime(text, userID string) string {
+	data, _ := h.storage.GetUserData(userID)
+	if data == nil {
+		return "❌ Напиши \"начать\" чтобы зарегистрироваться"
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(text, "напоминать за"))
+	amount := 1
+	if len(fields) > 0 {
+		if n, err := strconv.Atoi(fields[0]); err == nil && n > 0 {
+			amount = n
+			fields = fields[1:]
+		}
+	}
+	if len(fields) == 0 {
+		return "❌ Например: \"напоминать за 30 минут\" или \"напоминать за 2 часа\""
+	}
+
+	minutes := 0
+	switch {
+	case strings.HasPrefix(fields[0], "мин"):
+		minutes = amount
+	case strings.HasPrefix(fields[0], "час"):
+		minutes = amount * 60
+	case strings.HasPrefix(fields[0], "дн") || strings.HasPrefix(fields[0], "день"):
+		minutes = amount * 24 * 60
+	default:
+		return "❌ Например: \"напоминать за 30 минут\" или \"напоминать за 2 часа\""
+	}
+
+	data.Settings.ReminderLeadTime = minutes
+	if err := h.storage.SaveUserData(data); err != nil {
+		return "❌ Ошибка при сохранении настроек"
+	}
+	return fmt.Sprintf("✅ Буду напоминать о сроках задач за %s", formatMinutes(minutes))
+}
+
+// pendingReminders возвращает напоминания пользователя по времени срабатывания
+func (h *Handler) pendingReminders(userID string) []*models.Reminder {
+	reminders, _ := h.storage.GetUserReminders(userID)
+	sorted := append([]*models.Reminder{}, reminders...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].RemindAt.Before(sorted[j].RemindAt)
+	})
+	return sorted
+}
+
+// processReminders рассылает напоминания о сроках задач, просрочках и разовые напоминания
+func (h *Handler) processReminders(ctx context.Context, api *maxbot.Api, now time.Time) {
+	users, _ := h.storage.GetUsers()
+	for _, user := range users {
+		userID := user.MAXUserID
+
+		leadTime := 60
+		if data, _ := h.storage.GetUserData(userID); data != nil {
+			leadTime = data.Settings.ReminderLeadTime
+		}
+
+		tasks, _ := h.storage.GetUserTasks(userID)
+		for _, task := range tasks {
+			if task.Completed || task.Deadline == nil {
+				continue
+			}
+			deadline := *task.Deadline
+
+			switch {
+			case !task.OverdueNotified && !now.Before(deadline):
+				text := fmt.Sprintf("⚠️ Срок задачи истек: \"%s\" (%s)", task.Text, formatWhen(deadline))
+				if !h.notify(ctx, api, userID, notifyDeadline, text, h.taskReminderKeyboard(api, task)) {
+					continue
+				}
+				task.ReminderSent = true
+				task.OverdueNotified = true
+			case !task.ReminderSent && !now.Before(deadline.Add(-time.Duration(leadTime)*time.Minute)):
+				text := fmt.Sprintf("⏰ Скоро срок задачи: \"%s\" - %s", task.Text, formatWhen(deadline))
+				if !h.notify(ctx, api, userID, notifyDeadline, text, h.taskReminderKeyboard(api, task)) {
+					continue
+				}
+				task.ReminderSent = true
+			default:
+				continue
+			}
+			h.storage.UpdateTask(task)
+		}
+
+		reminders, _ := h.storage.GetUserReminders(userID)
+		for _, reminder := range append([]*models.Reminder{}, reminders...) {
+			if now.Before(reminder.RemindAt) {
+				continue
+			}
+			// Не дошедшее напоминание (или пришедшее при выключенных уведомлениях) не удаляем -
+			// повторим на следующем шаге
+			if h.notify(ctx, api, userID, notifyReminder, fmt.Sprintf("🔔 Напоминание: %s", reminder.Text), nil) {
+				h.storage.DeleteReminder(userID, reminder.ID)
+			}
+		}
+	}
+}
+
+func (h *Handler) taskReminderKeyboard(api *maxbot.Api, task *models.Task) *maxbot.Keyboard {
+	keyboard := api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().AddCallback("✅ Выполнено", schemes.POSITIVE, "task_complete_"+task.ID)
+	return keyboard
+}
+
+// formatMinutes выводит длительность в минутах в человекочитаемом виде
+func formatMinutes(minutes int) string {
+	switch {
+	case minutes >= 24*60 && minutes%(24*60) == 0:
+		return fmt.Sprintf("%d дн.", minutes/(24*60))
+	case minutes >= 60 && minutes%60 == 0:
+		return fmt.Sprintf("%d ч.", minutes/60)
+	default:
+		return fmt.Sprintf("%d мин.", minutes)
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/analytics"
+	"proddy-bot/internal/models"
+)
+
+// ========== WEEKLY AND MONTHLY REPORTS ==========
+
+// analyticsInput собирает историю пользователя для отчетов (включая архив задач)
+func (h *Handler) analyticsInput(userID string) analytics.Input {
+	sessions, _ := h.storage.GetUserPomodoroSessions(userID)
+	tasks, _ := h.storage.GetUserTasks(userID)
+	archived, _ := h.storage.GetArchivedTasks(userID)
+	goals, _ := h.storage.GetUserGoals(userID)
+
+	return analytics.Input{
+		Sessions: sessions,
+		Tasks:    append(append([]*models.Task{}, tasks...), archived...),
+		Goals:    goals,
+	}
+}
+
+// periodReport - отчет "статистика неделя" / "статистика месяц" со сравнением с прошлым периодом
+func (h *Handler) periodReport(userID string, monthly bool) string {
+	now := h.userNow(userID)
+	period, title, previousTitle := analytics.Week(now), "неделю", "прошлой неделе"
+	if monthly {
+		period, title, previousTitle = analytics.Month(now), "месяц", "прошлому месяцу"
+	}
+
+	input := h.analyticsInput(userID)
+	current := analytics.Build(input, period)
+	previous := analytics.Build(input, period.Previous())
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("📊 Отчет за %s (%s - %s)\n\n", title,
+		period.Start.Format("02.01"), period.End.AddDate(0, 0, -1).Format("02.01")))
+
+	response.WriteString(fmt.Sprintf("🎯 Фокус: %d мин., сессий: %d%s\n",
+		current.FocusMinutes, current.Sessions, formatChange(current.FocusMinutes, previous.FocusMinutes, previousTitle)))
+	if current.Distractions > 0 || previous.Distractions > 0 {
+		response.WriteString(fmt.Sprintf("💭 Отвлечений: %d (было %d)\n", current.Distractions, previous.Distractions))
+	}
+	if monthly {
+		writeWeeklyFocus(&response, current.FocusByDay)
+	} else {
+		writeDailyFocus(&response, current.FocusByDay)
+	}
+
+	response.WriteString(fmt.Sprintf("\n📝 Задачи: создано %d, выполнено %d%s\n",
+		current.TasksCreated, current.TasksCompleted, formatChange(current.TasksCompleted, previous.TasksCompleted, previousTitle)))
+	response.WriteString(fmt.Sprintf("🎯 Цели: продвинулись %d, достигнуто %d (было %d и %d)\n",
+		current.GoalsAdvanced, current.GoalsCompleted, previous.GoalsAdvanced, previous.GoalsCompleted))
+
+	if best, ok := bestFocusDay(current.FocusByDay); ok {
+		response.WriteString(fmt.Sprintf("\n🏅 Самый продуктивный день: %s %s - %d мин.", weekdayShortNames[best.Date.Weekday()], best.Date.Format("02.01"), best.Minutes))
+	}
+
+	return response.String()
+}
+
+// writeDailyFocus выводит минуты фокуса по дням полосками
+func writeDailyFocus(response *strings.Builder, days []analytics.DayFocus) {
+	max := 0
+	for _, day := range days {
+		if day.Minutes > max {
+			max = day.Minutes
+		}
+	}
+	for _, day := range days {
+		response.WriteString(fmt.Sprintf("%s %s %s %d\n", weekdayShortNames[day.Date.Weekday()], day.Date.Format("02.01"), focusBar(day.Minutes, max), day.Minutes))
+	}
+}
+
+// writeWeeklyFocus выводит минуты фокуса по неделям (для месячного отчета)
+func writeWeeklyFocus(response *strings.Builder, days []analytics.DayFocus) {
+	type week struct {
+		start, end time.Time
+		minutes    int
+	}
+	var weeks []week
+	for i, day := range days {
+		if i%7 == 0 {
+			weeks = append(weeks, week{start: day.Date})
+		}
+		weeks[len(weeks)-1].end = day.Date
+		weeks[len(weeks)-1].minutes += day.Minutes
+	}
+
+	max := 0
+	for _, w := range weeks {
+		if w.minutes > max {
+			max = w.minutes
+		}
+	}
+	for _, w := range weeks {
+		response.WriteString(fmt.Sprintf("%s-%s %s %d\n", w.start.Format("02.01"), w.end.Format("02.01"), focusBar(w.minutes, max), w.minutes))
+	}
+}
+
+func focusBar(value, max int) string {
+	const barLength = 8
+	if max == 0 {
+		return strings.Repeat("▫️", barLength)
+	}
+	filled := value * barLength / max
+	if value > 0 && filled == 0 {
+		filled = 1
+	}
+	return strings.Repeat("🟦", filled) + strings.Repeat("▫️", barLength-filled)
+}
+
+func bestFocusDay(days []analytics.DayFocus) (analytics.DayFocus, bool) {
+	var best analytics.DayFocus
+	for _, day := range days {
+		if day.Minutes > best.Minutes {
+			best = day
+		}
+	}
+	return best, best.Minutes > 0
+}
+
+// formatChange - сравнение с прошлым периодом: " (▲ 20% к прошлой неделе)"
+func formatChange(current, previous int, previousTitle string) string {
+	change, ok := analytics.Change(current, previous)
+	switch {
+	case !ok && current > 0:
+		return fmt.Sprintf(" (🆕 по %s данных нет)", previousTitle)
+	case !ok:
+		return ""
+	case change > 0:
+		return fmt.Sprintf(" (▲ %d%% к %s)", change, previousTitle)
+	case change < 0:
+		return fmt.Sprintf(" (▼ %d%% к %s)", -change, previousTitle)
+	default:
+		return " (= без изменений)"
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+)
+
+// Tick выполняет периодические задачи бота. Вызывается из главного цикла
+// раз в минуту, поэтому не пересекается с обработкой входящих обновлений.
+func (h *Handler) Tick(ctx context.Context, api *maxbot.Api, now time.Time) {
+	h.generateRecurringTasks(now)
+	h.processReminders(ctx, api, now)
+	h.processGoalCheckIns(ctx, api, now)
+	h.processHabitReminders(ctx, api, now)
+	h.processDigests(ctx, api, now)
+	h.processInactivityNudges(ctx, api, now)
+	h.deliverQueuedNotifications(ctx, api, now)
+	h.archiveCompletedTasks(now)
+	h.purgeDeleted(now)
+}
+
+// Timers - сработавшие таймеры помодоро. Главный цикл выполняет полученные функции
+// так же, как обрабатывает обновления, поэтому состояние помодоро меняется из одной горутины.
+func (h *Handler) Timers() <-chan func() {
+	return h.timerEvents
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+
+	"proddy-bot/internal/analytics"
+	"proddy-bot/internal/charts"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+)
+
+// ========== STATISTICS CHARTS ==========
+
+const (
+	// chartDays - за сколько дней строятся графики фокуса и задач
+	chartDays = 14
+	// heatmapWeeks - сколько недель показывает календарь активности
+	heatmapWeeks = 12
+)
+
+// sendStats отправляет статистику текстом, а следом - графики картинками
+func (h *Handler) sendStats(ctx context.Context, api *maxbot.Api, userID string, chatID int64) {
+	message := maxbot.NewMessage().SetChat(chatID).SetText(h.getStats(userID))
+	if _, err := api.Messages.Send(ctx, message); sendError(err) != nil {
+		fmt.Printf("❌ Error sending stats to %s: %v\n", userID, err)
+		return
+	}
+	h.sendStatsCharts(ctx, api, userID, chatID)
+}
+
+// sendStatsCharts рисует графики фокуса и задач и загружает их в MAX как фотографии
+func (h *Handler) sendStatsCharts(ctx context.Context, api *maxbot.Api, userID string, chatID int64) {
+	now := h.userNow(userID)
+	input := h.analyticsInput(userID)
+	recent := analytics.Build(input, analytics.LastDays(now, chartDays))
+	heatmapPeriod := analytics.LastDays(now, heatmapWeeks*7)
+	history := analytics.Build(input, heatmapPeriod)
+
+	if history.FocusMinutes == 0 && recent.TasksCreated == 0 && recent.TasksCompleted == 0 {
+		// Пустые графики ничего не расскажут
+		return
+	}
+
+	focus := make([]int, len(recent.FocusByDay))
+	for i, day := range recent.FocusByDay {
+		focus[i] = day.Minutes
+	}
+	created := make([]int, len(recent.TasksByDay))
+	completed := make([]int, len(recent.TasksByDay))
+	for i, day := range recent.TasksByDay {
+		created[i] = day.Created
+		completed[i] = day.Completed
+	}
+	activity := make([]int, len(history.FocusByDay))
+	for i, day := range history.FocusByDay {
+		activity[i] = day.Minutes
+	}
+
+	period := fmt.Sprintf("%s - %s", recent.Period.Start.Format("02.01"), now.Format("02.01"))
+	h.sendChart(ctx, api, chatID, charts.Bar(focus),
+		fmt.Sprintf("🎯 Минуты фокуса по дням (%s), всего %d мин.", period, recent.FocusMinutes))
+	h.sendChart(ctx, api, chatID, charts.Line(completed, created),
+		fmt.Sprintf("📝 Задачи по дням (%s): зеленая линия - выполнено (%d), серая - создано (%d)", period, recent.TasksCompleted, recent.TasksCreated))
+	h.sendChart(ctx, api, chatID, charts.Heatmap(activity, heatmapPeriod.Start.Weekday()),
+		fmt.Sprintf("🗓 Календарь фокуса за %d недель: чем темнее клетка, тем больше минут", heatmapWeeks))
+}
+
+// sendChart загружает картинку и отправляет ее с подписью
+func (h *Handler) sendChart(ctx context.Context, api *maxbot.Api, chatID int64, img image.Image, caption string) {
+	data, err := charts.EncodePNG(img)
+	if err != nil {
+		fmt.Printf("❌ Error encoding chart: %v\n", err)
+		return
+	}
+
+	photo, err := api.Uploads.UploadPhotoFromReader(ctx, bytes.NewReader(data))
+	if err != nil {
+		fmt.Printf("❌ Error uploading chart: %v\n", err)
+		return
+	}
+
+	message := maxbot.NewMessage().SetChat(chatID).SetText(caption).AddPhoto(photo)
+	if _, err := api.Messages.Send(ctx, message); sendError(err) != nil {
+		fmt.Printf("❌ Error sending chart: %v\n", err)
+	}
+}
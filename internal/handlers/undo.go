@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"proddy-bot/internal/models"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// ========== UNDO FUNCTIONALITY ==========
+
+// undoWindow - сколько времени после действия его можно отменить
+const undoWindow = 5 * time.Minute
+
+// undoAction описывает обратимое действие пользователя
+type undoAction struct {
+	token   string
+	title   string
+	expires time.Time
+	undo    func(ctx context.Context, api *maxbot.Api, chatID int64) string
+}
+
+// pushUndo запоминает действие, которое можно отменить, и возвращает его токен для кнопки
+func (h *Handler) pushUndo(userID, title string, undo func(ctx context.Context, api *maxbot.Api, chatID int64) string) string {
+	now := time.Now()
+
+	// Заодно выбрасываем просроченные действия
+	actions := h.undoActions[userID][:0]
+	for _, action := range h.undoActions[userID] {
+		if action.expires.After(now) {
+			actions = append(actions, action)
+		}
+	}
+
+	action := &undoAction{
+		token:   newID(),
+		title:   title,
+		expires: now.Add(undoWindow),
+		undo:    undo,
+	}
+	h.undoActions[userID] = append(actions, action)
+	return action.token
+}
+
+// undoLast отменяет последнее действие пользователя (текстовая команда "отменить")
+func (h *Handler) undoLast(ctx context.Context, api *maxbot.Api, userID string, chatID int64) string {
+	actions := h.undoActions[userID]
+	if len(actions) == 0 {
+		return "🤷 Нечего отменять"
+	}
+	return h.runUndo(ctx, api, userID, chatID, actions[len(actions)-1].token)
+}
+
+func (h *Handler) runUndo(ctx context.Context, api *maxbot.Api, userID string, chatID int64, token string) string {
+	actions := h.undoActions[userID]
+	for i, action := range actions {
+		if action.token != token {
+			continue
+		}
+		h.undoActions[userID] = append(actions[:i], actions[i+1:]...)
+		if time.Now().After(action.expires) {
+			return fmt.Sprintf("⌛ Время на отмену истекло (%s)", action.title)
+		}
+		return action.undo(ctx, api, chatID)
+	}
+	return "⌛ Это действие уже нельзя отменить"
+}
+
+func (h *Handler) handleUndoCallback(ctx context.Context, api *maxbot.Api, upd *schemes.MessageCallbackUpdate, userID string, chatID int64) {
+	token := strings.TrimPrefix(upd.Callback.Payload, "undo_")
+	response := h.runUndo(ctx, api, userID, chatID, token)
+	if response != "" {
+		api.Messages.Send(ctx, maxbot.NewMessage().SetChat(chatID).SetText(response))
+	}
+}
+
+// sendWithUndo отправляет ответ на обратимое действие с кнопкой отмены
+func (h *Handler) sendWithUndo(ctx context.Context, api *maxbot.Api, chatID int64, text, token string) {
+	message := maxbot.NewMessage().SetChat(chatID).SetText(text).AddKeyboard(undoKeyboard(api, token))
+	if _, err := api.Messages.Send(ctx, message); sendError(err) != nil {
+		fmt.Printf("❌ Error sending message: %v\n", err)
+	}
+}
+
+// undoKeyboard - клавиатура с кнопкой отмены, к которой можно добавить свои кнопки
+func undoKeyboard(api *maxbot.Api, token string) *maxbot.Keyboard {
+	keyboard := api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().AddCallback("↩ Отменить", schemes.NEGATIVE, "undo_"+token)
+	return keyboard
+}
+
+// deleteTaskWithUndo мягко удаляет задачу и регистрирует отмену удаления
+func (h *Handler) deleteTaskWithUndo(task *models.Task) (string, error) {
+	if err := h.storage.DeleteTask(task.UserID, task.ID); err != nil {
+		return "", err
+	}
+	h.syncGoalWithTasks(task.UserID, task.GoalID)
+
+	token := h.pushUndo(task.UserID, "удаление задачи", func(ctx context.Context, api *maxbot.Api, chatID int64) string {
+		if err := h.storage.UndeleteTask(task.UserID, task.ID); err != nil {
+			return "❌ Задача уже удалена окончательно"
+		}
+		h.syncGoalWithTasks(task.UserID, task.GoalID)
+		return fmt.Sprintf("↩ Задача восстановлена: \"%s\"", task.Text)
+	})
+	return token, nil
+}
+
+// completeTaskWithUndo отмечает задачу выполненной и регистрирует отмену.
+// При отмене убирается и созданный следующий экземпляр повторяющейся задачи.
+// Вторым значением возвращается цель, достигнутая выполнением задачи.
+func (h *Handler) completeTaskWithUndo(task *models.Task) (string, *models.Goal, error) {
+	cursor := h.seriesCursor(task.UserID, task.SeriesID)
+	next, achieved, err := h.markTaskCompleted(task)
+	if err != nil {
+		return "", nil, err
+	}
+
+	token := h.pushUndo(task.UserID, "выполнение задачи", func(ctx context.Context, api *maxbot.Api, chatID int64) string {
+		if task.ArchivedAt != nil || task.DeletedAt != nil {
+			return "❌ Задача уже в архиве или удалена"
+		}
+		task.Completed = false
+		task.CompletedAt = nil
+		if err := h.storage.UpdateTask(task); err != nil {
+			return "❌ Ошибка при обновлении задачи"
+		}
+		if next != nil && !next.Completed {
+			h.storage.DeleteTask(next.UserID, next.ID)
+			h.setSeriesCursor(next.UserID, next.SeriesID, cursor)
+		}
+		h.syncGoalWithTasks(task.UserID, task.GoalID)
+		return fmt.Sprintf("↩ Задача снова в работе: \"%s\"", task.Text)
+	})
+	return token, achieved, nil
+}
+
+// purgeDeleted окончательно удаляет задачи и цели, отмена удаления которых больше недоступна
+func (h *Handler) purgeDeleted(now time.Time) {
+	h.storage.PurgeDeleted(now.Add(-undoWindow))
+}
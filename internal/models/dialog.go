@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// DialogState - состояние многошагового диалога (добавление задачи, настройки и т.п.)
+type DialogState struct {
+    UserID  string            `json:"user_id"`
+    Flow    string            `json:"flow"`
+    Step    int               `json:"step"`
+    Data    map[string]string `json:"data"`
+    Updated time.Time         `json:"updated"`
+}
\ No newline at end of file
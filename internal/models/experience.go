@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Experience - опыт, уровень и достижения пользователя
+type Experience struct {
+    UserID       string               `json:"user_id"`
+    XP           int                  `json:"xp"`
+    Achievements map[string]time.Time `json:"achievements"` // ID достижения -> когда получено
+    Rewarded     map[string]bool      `json:"rewarded"`     // события, за которые уже начислен опыт
+}
\ No newline at end of file
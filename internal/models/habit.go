@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Habit - привычка, которую пользователь отмечает по дням
+type Habit struct {
+    ID         string      `json:"id"`
+    UserID     string      `json:"user_id"`
+    Title      string      `json:"title"`
+    Created    time.Time   `json:"created"`
+    Schedule   *Recurrence `json:"schedule"`   // в какие дни нужно выполнять
+    DoneDates  []string    `json:"done_dates"` // дни выполнения в формате "2006-01-02"
+    RemindedOn string      `json:"reminded_on,omitempty"` // день последнего напоминания
+}
\ No newline at end of file
@@ -11,6 +11,17 @@ type PomodoroSession struct {
     Completed   bool      `json:"completed"`
     Type        string    `json:"type"` // "work", "short_break", "long_break"
     Interrupted bool      `json:"interrupted"`
+    FocusedMinutes  int    `json:"focused_minutes,omitempty"`  // сколько минут фокуса засчитано за прерванную сессию
+    InterruptReason string `json:"interrupt_reason,omitempty"` // причина остановки, см. handlers.interruptReasons
+    Distractions []Distraction `json:"distractions,omitempty"`
+    TaskID       string        `json:"task_id,omitempty"` // задача, над которой шла сессия
+}
+
+// Distraction - мысль, отвлекшая от фокуса во время сессии
+type Distraction struct {
+    Text   string    `json:"text"`
+    Time   time.Time `json:"time"`
+    TaskID string    `json:"task_id,omitempty"` // задача, созданная из отвлечения
 }
 
 type PomodoroStats struct {
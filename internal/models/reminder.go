@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type Reminder struct {
+    ID       string    `json:"id"`
+    UserID   string    `json:"user_id"`
+    Text     string    `json:"text"`
+    Created  time.Time `json:"created"`
+    RemindAt time.Time `json:"remind_at"`
+}
\ No newline at end of file
@@ -11,6 +11,37 @@ type Task struct {
     Completed bool       `json:"completed"`
     Priority  string     `json:"priority"` // "low", "medium", "high"
     Category  string     `json:"category"` // "study", "work", "personal"
+    History   []TaskEdit `json:"history,omitempty"`
+    Items     []ChecklistItem `json:"items,omitempty"`
+    SeriesID  string      `json:"series_id,omitempty"`
+    Recurrence *Recurrence `json:"recurrence,omitempty"`
+    ReminderSent    bool `json:"reminder_sent"`
+    OverdueNotified bool `json:"overdue_notified"`
+    CompletedAt *time.Time `json:"completed_at,omitempty"`
+    ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+    DeletedAt   *time.Time `json:"deleted_at,omitempty"` // мягкое удаление, пока доступна отмена
+    GoalID      string     `json:"goal_id,omitempty"`      // цель, к которой привязана задача
+    GoalStepID  string     `json:"goal_step_id,omitempty"` // шаг цели, к которому привязана задача
+    Estimate    int        `json:"estimate,omitempty"`     // оценка в помодоро-сессиях
+}
+
+type Recurrence struct {
+    Frequency  string         `json:"frequency"` // "daily", "weekdays", "weekly", "monthly"
+    Weekdays   []time.Weekday `json:"weekdays,omitempty"`     // для "weekly"
+    DayOfMonth int            `json:"day_of_month,omitempty"` // для "monthly"
+}
+
+type ChecklistItem struct {
+    ID        string `json:"id"`
+    Text      string `json:"text"`
+    Completed bool   `json:"completed"`
+}
+
+type TaskEdit struct {
+    Field    string    `json:"field"` // "text", "deadline", "priority", "category"
+    OldValue string    `json:"old_value"`
+    NewValue string    `json:"new_value"`
+    Changed  time.Time `json:"changed"`
 }
 
 type Goal struct {
@@ -22,7 +53,26 @@ type Goal struct {
     Deadline    time.Time  `json:"deadline"`
     Steps       []GoalStep `json:"steps"`
     Progress    int        `json:"progress"` // 0-100%
+    ProgressFromTasks bool `json:"progress_from_tasks"` // прогресс считается по привязанным задачам
+    Target      float64    `json:"target,omitempty"`  // целевое значение измеримой цели
+    Unit        string     `json:"unit,omitempty"`    // единица измерения: "км", "книг"
+    Current     float64    `json:"current,omitempty"` // текущее значение
+    CheckIns    []GoalCheckIn `json:"check_ins,omitempty"`
+    CheckInDay  *time.Weekday `json:"check_in_day,omitempty"` // день еженедельной проверки, nil - выключена
+    CheckInAskedAt *time.Time `json:"check_in_asked_at,omitempty"`
+    NudgedAt    *time.Time `json:"nudged_at,omitempty"`
     Completed   bool       `json:"completed"`
+    CompletedAt *time.Time `json:"completed_at,omitempty"`
+    DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// GoalCheckIn - отметка о продвижении к цели
+type GoalCheckIn struct {
+    Date     time.Time `json:"date"`
+    Delta    float64   `json:"delta,omitempty"` // изменение значения измеримой цели
+    Value    float64   `json:"value,omitempty"` // значение после отметки
+    Progress int       `json:"progress"`
+    Note     string    `json:"note,omitempty"`
 }
 
 type GoalStep struct {
@@ -9,6 +9,7 @@ type User struct {
     Username        string    `json:"username"`
     RegistrationDate time.Time `json:"registration_date"`
     LastActivity    time.Time `json:"last_activity"`
+    ChatID          int64     `json:"chat_id"` // диалог с ботом для уведомлений
 }
 
 type UserData struct {
@@ -17,10 +18,25 @@ type UserData struct {
     Tasks            []Task           `json:"tasks"`
     Goals            []Goal           `json:"goals"`
     Settings         UserSettings     `json:"settings"`
+    DigestSentOn     string           `json:"digest_sent_on,omitempty"` // день последней утренней сводки
+    ReviewSentOn     string           `json:"review_sent_on,omitempty"` // день последнего вечернего итога
+    NudgedAt         *time.Time       `json:"nudged_at,omitempty"`      // когда последний раз звали вернуться
+    NudgeCount       int              `json:"nudge_count,omitempty"`    // сколько раз звали с последнего визита
+    SeriesCursors    map[string]time.Time `json:"series_cursors,omitempty"` // seriesID -> срок последнего созданного экземпляра повторяющейся задачи
 }
 
 type UserSettings struct {
     PomodoroWorkDuration int `json:"pomodoro_work_duration"` // в минутах
     PomodoroBreakDuration int `json:"pomodoro_break_duration"`
     NotificationsEnabled bool `json:"notifications_enabled"`
+    ReminderLeadTime int `json:"reminder_lead_time"` // за сколько минут до срока напоминать
+    ArchiveAfterDays int `json:"archive_after_days"` // через сколько дней выполненные задачи уходят в архив
+    GoalStaleDays int `json:"goal_stale_days"` // через сколько дней без обновлений напомнить о цели
+    UTCOffset *int `json:"utc_offset,omitempty"` // часовой пояс: смещение от UTC в минутах, nil - время сервера
+    MorningDigestAt string `json:"morning_digest_at,omitempty"` // время утренней сводки "08:00", пусто - выключена
+    EveningReviewAt string `json:"evening_review_at,omitempty"` // время вечернего итога "21:00", пусто - выключен
+    DisabledNotifications map[string]bool `json:"disabled_notifications,omitempty"` // выключенные виды уведомлений: "pomodoro", "reminders", "digests", "checkins"
+    QuietHoursStart string `json:"quiet_hours_start,omitempty"` // начало тихих часов "23:00", пусто - выключены
+    QuietHoursEnd string `json:"quiet_hours_end,omitempty"` // конец тихих часов "08:00"
+    InactivityDays int `json:"inactivity_days"` // через сколько дней без визитов позвать вернуться, 0 - не звать
 }
\ No newline at end of file
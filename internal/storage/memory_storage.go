@@ -1,20 +1,29 @@
 package storage
 
 import (
+	"errors"
 	"sync"
 	"time"
 	
 	"proddy-bot/internal/models"
 )
 
+// ErrNotFound is returned when an updated entity does not exist
+var ErrNotFound = errors.New("not found")
+
 type MemoryStorage struct {
 	mu           sync.RWMutex
 	users        map[string]*models.User
 	userData     map[string]*models.UserData
 	pomodoroStats map[string]*models.PomodoroStats
 	tasks        map[string][]*models.Task    // userID -> tasks
+	archivedTasks map[string][]*models.Task   // userID -> archived tasks
 	goals        map[string][]*models.Goal    // userID -> goals
 	pomodoroSessions map[string][]*models.PomodoroSession // userID -> sessions
+	reminders    map[string][]*models.Reminder // userID -> reminders
+	dialogs      map[string]*models.DialogState // userID -> active dialog
+	habits       map[string][]*models.Habit     // userID -> habits
+	experience   map[string]*models.Experience  // userID -> XP and achievements
 }
 
 func NewMemoryStorage() *MemoryStorage {
@@ -23,8 +32,13 @@ func NewMemoryStorage() *MemoryStorage {
 		userData:        make(map[string]*models.UserData),
 		pomodoroStats:   make(map[string]*models.PomodoroStats),
 		tasks:           make(map[string][]*models.Task),
+		archivedTasks:   make(map[string][]*models.Task),
 		goals:           make(map[string][]*models.Goal),
 		pomodoroSessions: make(map[string][]*models.PomodoroSession),
+		reminders:       make(map[string][]*models.Reminder),
+		dialogs:         make(map[string]*models.DialogState),
+		habits:          make(map[string][]*models.Habit),
+		experience:      make(map[string]*models.Experience),
 	}
 }
 
@@ -50,6 +64,10 @@ func (s *MemoryStorage) SaveUser(user *models.User) error {
 				PomodoroWorkDuration:  25,
 				PomodoroBreakDuration: 5,
 				NotificationsEnabled:  true,
+				ReminderLeadTime:      60,
+				ArchiveAfterDays:      7,
+				GoalStaleDays:         7,
+				InactivityDays:        3,
 			},
 		}
 	}
@@ -68,6 +86,17 @@ func (s *MemoryStorage) GetUser(userID string) (*models.User, error) {
 	return user, nil
 }
 
+func (s *MemoryStorage) GetUsers() ([]*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	users := make([]*models.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
 func (s *MemoryStorage) UpdateUserActivity(userID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -115,29 +144,102 @@ func (s *MemoryStorage) GetUserTasks(userID string) ([]*models.Task, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	
-	tasks, exists := s.tasks[userID]
-	if !exists {
-		return []*models.Task{}, nil
+	tasks := []*models.Task{}
+	for _, task := range s.tasks[userID] {
+		if task.DeletedAt == nil {
+			tasks = append(tasks, task)
+		}
 	}
 	return tasks, nil
 }
 
+func (s *MemoryStorage) UpdateTask(task *models.Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	tasks := s.tasks[task.UserID]
+	for i, t := range tasks {
+		if t.ID == task.ID {
+			tasks[i] = task
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// DeleteTask soft-deletes a task: it disappears from GetUserTasks but keeps
+// its position until PurgeDeleted, so the deletion can be undone
 func (s *MemoryStorage) DeleteTask(userID, taskID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	
-	tasks, exists := s.tasks[userID]
-	if !exists {
-		return nil
+	for _, task := range s.tasks[userID] {
+		if task.ID == taskID {
+			now := time.Now()
+			task.DeletedAt = &now
+			break
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStorage) UndeleteTask(userID, taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for _, task := range s.tasks[userID] {
+		if task.ID == taskID && task.DeletedAt != nil {
+			task.DeletedAt = nil
+			return nil
+		}
 	}
+	return ErrNotFound
+}
+
+// ArchiveTask moves a task from the active list to the archive
+func (s *MemoryStorage) ArchiveTask(userID, taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	
+	tasks := s.tasks[userID]
 	for i, task := range tasks {
 		if task.ID == taskID {
+			now := time.Now()
+			task.ArchivedAt = &now
 			s.tasks[userID] = append(tasks[:i], tasks[i+1:]...)
-			break
+			s.archivedTasks[userID] = append(s.archivedTasks[userID], task)
+			return nil
 		}
 	}
-	return nil
+	return ErrNotFound
+}
+
+// RestoreTask moves an archived task back to the end of the active list
+func (s *MemoryStorage) RestoreTask(userID, taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	archived := s.archivedTasks[userID]
+	for i, task := range archived {
+		if task.ID == taskID {
+			task.ArchivedAt = nil
+			s.archivedTasks[userID] = append(archived[:i], archived[i+1:]...)
+			s.tasks[userID] = append(s.tasks[userID], task)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStorage) GetArchivedTasks(userID string) ([]*models.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	tasks, exists := s.archivedTasks[userID]
+	if !exists {
+		return []*models.Task{}, nil
+	}
+	return tasks, nil
 }
 
 // Goal methods
@@ -157,13 +259,83 @@ func (s *MemoryStorage) GetUserGoals(userID string) ([]*models.Goal, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	
-	goals, exists := s.goals[userID]
-	if !exists {
-		return []*models.Goal{}, nil
+	goals := []*models.Goal{}
+	for _, goal := range s.goals[userID] {
+		if goal.DeletedAt == nil {
+			goals = append(goals, goal)
+		}
 	}
 	return goals, nil
 }
 
+func (s *MemoryStorage) UpdateGoal(goal *models.Goal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	goals := s.goals[goal.UserID]
+	for i, g := range goals {
+		if g.ID == goal.ID {
+			goals[i] = goal
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// DeleteGoal soft-deletes a goal, see DeleteTask
+func (s *MemoryStorage) DeleteGoal(userID, goalID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for _, goal := range s.goals[userID] {
+		if goal.ID == goalID {
+			now := time.Now()
+			goal.DeletedAt = &now
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStorage) UndeleteGoal(userID, goalID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for _, goal := range s.goals[userID] {
+		if goal.ID == goalID && goal.DeletedAt != nil {
+			goal.DeletedAt = nil
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// PurgeDeleted permanently removes tasks and goals soft-deleted before the given time
+func (s *MemoryStorage) PurgeDeleted(before time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for userID, tasks := range s.tasks {
+		kept := tasks[:0]
+		for _, task := range tasks {
+			if task.DeletedAt == nil || task.DeletedAt.After(before) {
+				kept = append(kept, task)
+			}
+		}
+		s.tasks[userID] = kept
+	}
+	
+	for userID, goals := range s.goals {
+		kept := goals[:0]
+		for _, goal := range goals {
+			if goal.DeletedAt == nil || goal.DeletedAt.After(before) {
+				kept = append(kept, goal)
+			}
+		}
+		s.goals[userID] = kept
+	}
+}
+
 // Pomodoro methods
 func (s *MemoryStorage) SavePomodoroSession(session *models.PomodoroSession) error {
 	s.mu.Lock()
@@ -173,6 +345,20 @@ func (s *MemoryStorage) SavePomodoroSession(session *models.PomodoroSession) err
 	return nil
 }
 
+func (s *MemoryStorage) UpdatePomodoroSession(session *models.PomodoroSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	sessions := s.pomodoroSessions[session.UserID]
+	for i, existing := range sessions {
+		if existing.ID == session.ID {
+			sessions[i] = session
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
 func (s *MemoryStorage) GetUserPomodoroSessions(userID string) ([]*models.PomodoroSession, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -204,4 +390,147 @@ func (s *MemoryStorage) UpdatePomodoroStats(stats *models.PomodoroStats) error {
 	
 	s.pomodoroStats[stats.UserID] = stats
 	return nil
+}
+
+// Reminder methods
+func (s *MemoryStorage) SaveReminder(reminder *models.Reminder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	if reminder.Created.IsZero() {
+		reminder.Created = time.Now()
+	}
+	
+	s.reminders[reminder.UserID] = append(s.reminders[reminder.UserID], reminder)
+	return nil
+}
+
+func (s *MemoryStorage) GetUserReminders(userID string) ([]*models.Reminder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	reminders, exists := s.reminders[userID]
+	if !exists {
+		return []*models.Reminder{}, nil
+	}
+	return reminders, nil
+}
+
+func (s *MemoryStorage) DeleteReminder(userID, reminderID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	reminders := s.reminders[userID]
+	for i, reminder := range reminders {
+		if reminder.ID == reminderID {
+			s.reminders[userID] = append(reminders[:i], reminders[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// Dialog methods
+func (s *MemoryStorage) GetDialogState(userID string) (*models.DialogState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	state, exists := s.dialogs[userID]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return state, nil
+}
+
+func (s *MemoryStorage) SaveDialogState(state *models.DialogState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	state.Updated = time.Now()
+	s.dialogs[state.UserID] = state
+	return nil
+}
+
+func (s *MemoryStorage) DeleteDialogState(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	delete(s.dialogs, userID)
+	return nil
+}
+
+// Habit methods
+func (s *MemoryStorage) SaveHabit(habit *models.Habit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	if habit.Created.IsZero() {
+		habit.Created = time.Now()
+	}
+	
+	s.habits[habit.UserID] = append(s.habits[habit.UserID], habit)
+	return nil
+}
+
+func (s *MemoryStorage) GetUserHabits(userID string) ([]*models.Habit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	habits, exists := s.habits[userID]
+	if !exists {
+		return []*models.Habit{}, nil
+	}
+	return habits, nil
+}
+
+func (s *MemoryStorage) UpdateHabit(habit *models.Habit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	habits := s.habits[habit.UserID]
+	for i, h := range habits {
+		if h.ID == habit.ID {
+			habits[i] = habit
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStorage) DeleteHabit(userID, habitID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	habits := s.habits[userID]
+	for i, habit := range habits {
+		if habit.ID == habitID {
+			s.habits[userID] = append(habits[:i], habits[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// Experience methods
+func (s *MemoryStorage) GetExperience(userID string) (*models.Experience, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	experience, exists := s.experience[userID]
+	if !exists {
+		return &models.Experience{
+			UserID:       userID,
+			Achievements: make(map[string]time.Time),
+			Rewarded:     make(map[string]bool),
+		}, nil
+	}
+	return experience, nil
+}
+
+func (s *MemoryStorage) SaveExperience(experience *models.Experience) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.experience[experience.UserID] = experience
+	return nil
 }
\ No newline at end of file